@@ -1,14 +1,25 @@
 package action
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/mirrors"
 	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/glide/repo"
 )
 
 // CacheClear clears the Glide cache
 func CacheClear() {
+	if err := cache.ExclusiveSystemLock(); err != nil {
+		msg.Die("Unable to lock the cache: %s", err)
+	}
 	l := cache.Location()
 
 	err := os.RemoveAll(l)
@@ -21,3 +32,175 @@ func CacheClear() {
 
 	msg.Info("Glide cache has been cleared.")
 }
+
+// ServeCache serves the repositories in the Glide cache over HTTP so other
+// instances of Glide can use it as their proxy.
+func ServeCache(addr string) {
+	if err := mirrors.Load(); err != nil {
+		msg.Err("Unable to load mirrors: %s", err)
+	}
+
+	msg.Info("Serving the cache at %s on %s", cache.Location(), addr)
+	if err := http.ListenAndServe(addr, repo.CacheHandler()); err != nil {
+		msg.Die("Unable to serve the cache: %s", err)
+	}
+}
+
+// CacheList lists the repositories in the Glide cache.
+//
+// Params:
+//   - format (string): The format to output (text, json, json-pretty)
+func CacheList(format string) {
+	if format != textFormat && format != jsonFormat && format != jsonPrettyFormat {
+		msg.Die("invalid output format: must be one of: json|json-pretty|text")
+	}
+
+	entries, err := repo.CacheEntries()
+	if err != nil && !os.IsNotExist(err) {
+		msg.Die("Unable to read the cache: %s", err)
+	}
+
+	switch format {
+	case textFormat:
+		w := tabwriter.NewWriter(msg.Default.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tORIGIN\tSIZE\tLAST USED")
+		for _, e := range entries {
+			used := ""
+			if !e.LastUse.IsZero() {
+				used = e.LastUse.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Key, orDash(e.Origin), formatSize(e.Size), orDash(used))
+		}
+		w.Flush()
+	case jsonFormat:
+		json.NewEncoder(msg.Default.Stdout).Encode(entries)
+	case jsonPrettyFormat:
+		b, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			msg.Die("could not marshal cache entries: %s", err)
+		}
+		msg.Puts("%s", b)
+	}
+}
+
+// CacheGC removes repositories from the Glide cache that have not been used
+// recently or do not fit in a maximum size and compacts the rest.
+//
+// Params:
+//   - keepDays (int): Keep repositories used within this many days. 0 keeps all.
+//   - maxSize (string): The maximum size of the cache, such as 500M or 2G.
+//   - gitGC (bool): Run git gc on the remaining Git repositories.
+//   - dryRun (bool): Report what would be removed without removing it.
+func CacheGC(keepDays int, maxSize string, gitGC, dryRun bool) {
+	if keepDays < 0 {
+		msg.Die("--keep-days must not be negative")
+	}
+	size, err := parseSize(maxSize)
+	if err != nil {
+		msg.Die("%s", err)
+	}
+
+	systemLock()
+	res, err := repo.CacheGC(repo.GCOptions{
+		KeepDays: keepDays,
+		MaxSize:  size,
+		GitGC:    gitGC,
+		DryRun:   dryRun,
+	})
+	if err != nil {
+		msg.Die("Unable to clean the cache: %s", err)
+	}
+
+	if dryRun {
+		msg.Info("%d repositories would be removed reclaiming %s", len(res.Removed), formatSize(res.Before-res.After))
+		return
+	}
+	msg.Info("Removed %d repositories and reclaimed %s. The cache is now %s.", len(res.Removed), formatSize(res.Before-res.After), formatSize(res.After))
+}
+
+// parseSize parses a size in bytes with an optional K, M, G, or T suffix.
+// An empty size is 0.
+func parseSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	if s == "" {
+		return 0, nil
+	}
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+
+	mult := int64(1)
+	if i := strings.IndexAny(s, "KMGT"); i == len(s)-1 && i > 0 {
+		for _, u := range "KMGT" {
+			mult *= 1024
+			if byte(u) == s[i] {
+				break
+			}
+		}
+		s = s[:i]
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("Invalid size %q. Use a number of bytes with an optional K, M, G, or T suffix", size)
+	}
+
+	return int64(n * float64(mult)), nil
+}
+
+// formatSize formats a number of bytes for people to read.
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 3; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// CacheVerify checks the repositories in the Glide cache for corruption, such
+// as a clone that was interrupted, and optionally repairs them.
+//
+// Params:
+//   - repair (bool): Clone corrupt repositories again.
+//
+// Returns true when problems remain.
+func CacheVerify(repair bool) bool {
+	systemLock()
+
+	checks, err := repo.VerifyCache(repair)
+	if err != nil && !os.IsNotExist(err) {
+		msg.Die("Unable to verify the cache: %s", err)
+	}
+
+	problems := 0
+	for _, c := range checks {
+		switch {
+		case c.Repaired:
+			msg.Info("%s was repaired: %s", c.Key, c.Problem)
+		case c.Problem != "":
+			msg.Err("%s (%s): %s", c.Key, orDash(c.Origin), c.Problem)
+			problems++
+		}
+	}
+
+	if problems > 0 {
+		if !repair {
+			msg.Info("Run 'glide cache verify --repair' to clone the corrupt repositories again.")
+		}
+		return true
+	}
+	msg.Info("Verified %d repositories in the cache.", len(checks))
+	return false
+}
+
+// systemLock takes the system wide lock on the Glide cache or exits when it
+// can not.
+func systemLock() {
+	if err := cache.SystemLock(); err != nil {
+		msg.Die("Unable to lock the cache: %s", err)
+	}
+}
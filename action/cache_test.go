@@ -0,0 +1,47 @@
+package action
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"":      0,
+		"100":   100,
+		"2K":    2048,
+		"1.5M":  1572864,
+		"2G":    2147483648,
+		"2gb":   2147483648,
+		"1GiB":  1073741824,
+		" 10k ": 10240,
+	}
+	for in, want := range tests {
+		got, err := parseSize(in)
+		if err != nil {
+			t.Errorf("parseSize(%q) returned error: %s", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("parseSize(%q) = %d, want %d", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"K", "abc", "-1", "2X"} {
+		if _, err := parseSize(in); err == nil {
+			t.Errorf("parseSize(%q) expected an error", in)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		0:          "0B",
+		1023:       "1023B",
+		1536:       "1.5K",
+		1572864:    "1.5M",
+		2147483648: "2.0G",
+	}
+	for in, want := range tests {
+		if got := formatSize(in); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
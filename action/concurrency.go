@@ -0,0 +1,75 @@
+package action
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/Masterminds/glide/cfg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/repo"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// concurrencyFlags are the limits set with flags. They take precedence
+	// over the glide.yaml file, which takes precedence over the settings in
+	// the Glide home.
+	concurrencyFlags = &cfg.Concurrency{}
+	homeConcurrency  *cfg.Concurrency
+)
+
+// Concurrency sets the number of jobs, the number of fetches allowed from one
+// host at the same time, and the number of fetches started per second. Zero
+// values are taken from the settings.yaml file in the Glide home, if they are
+// set there.
+func Concurrency(jobs, hostJobs int, fetchRate float64) error {
+	if jobs < 0 || hostJobs < 0 || fetchRate < 0 {
+		return fmt.Errorf("invalid concurrency: --jobs, --host-jobs, and --fetch-rate can not be negative")
+	}
+	concurrencyFlags = &cfg.Concurrency{
+		Jobs:      jobs,
+		HostJobs:  hostJobs,
+		FetchRate: fetchRate,
+	}
+
+	var err error
+	homeConcurrency, err = readHomeConcurrency()
+	if err != nil {
+		return err
+	}
+	repo.SetConcurrency(concurrencyFlags.Merge(homeConcurrency))
+
+	return nil
+}
+
+// configConcurrency applies the concurrency settings from a glide.yaml file.
+func configConcurrency(c *cfg.Concurrency) {
+	if c == nil {
+		return
+	}
+
+	repo.SetConcurrency(concurrencyFlags.Merge(c).Merge(homeConcurrency))
+}
+
+// readHomeConcurrency reads the concurrency section of the settings.yaml file
+// in the Glide home.
+func readHomeConcurrency() (*cfg.Concurrency, error) {
+	p := filepath.Join(gpath.Home(), "settings.yaml")
+	yml, err := ioutil.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	s := struct {
+		Concurrency *cfg.Concurrency `yaml:"concurrency"`
+	}{}
+	if err := yaml.Unmarshal(yml, &s); err != nil {
+		return nil, fmt.Errorf("Unable to parse %s: %s", p, err)
+	}
+
+	return s.Concurrency, nil
+}
@@ -18,7 +18,7 @@ import (
 // ConfigWizard reads configuration from a glide.yaml file and attempts to suggest
 // improvements. The wizard is interactive.
 func ConfigWizard(base string) {
-	cache.SystemLock()
+	systemLock()
 	_, err := gpath.Glide()
 	glidefile := gpath.GlideFile
 	if err != nil {
@@ -272,6 +272,9 @@ func wizardFindVersions(d *cfg.Dependency) {
 	var useLocal bool
 	if _, err = os.Stat(local); err == nil {
 		useLocal = true
+	} else if cache.Offline {
+		msg.Debug("Skipping version lookup for %s in offline mode", remote)
+		return
 	}
 
 	// Git endpoints allow for querying without fetching the codebase locally.
@@ -298,7 +301,10 @@ func wizardFindVersions(d *cfg.Dependency) {
 	}
 
 	if cc {
-		cache.Lock(key)
+		if err := cache.Lock(key); err != nil {
+			msg.Warn("Unable to look up the versions of %s: %s", d.Name, err)
+			return
+		}
 		cache.MemTouch(remote)
 		if _, err = os.Stat(local); os.IsNotExist(err) {
 			repo.Get()
@@ -308,7 +314,7 @@ func wizardFindVersions(d *cfg.Dependency) {
 			if err != nil {
 				msg.Debug("Error saving cache repo details: %s", err)
 			}
-		} else {
+		} else if !cache.Offline {
 			repo.Update()
 		}
 		tgs, err := repo.Tags()
@@ -7,9 +7,11 @@ import (
 	"strings"
 
 	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dep"
 	"github.com/Masterminds/glide/dependency"
 	"github.com/Masterminds/glide/gb"
 	"github.com/Masterminds/glide/godep"
+	"github.com/Masterminds/glide/govendor"
 	"github.com/Masterminds/glide/gpm"
 	"github.com/Masterminds/glide/msg"
 	gpath "github.com/Masterminds/glide/path"
@@ -204,6 +206,15 @@ func guessImportDeps(base string, config *cfg.Config) {
 	} else if d, ok := guessImportGB(absBase); ok {
 		msg.Info("Importing GB configuration")
 		deps = d
+	} else if d, ok := guessImportGovendor(absBase); ok {
+		msg.Info("Importing govendor configuration")
+		deps = d
+	} else if d, ok := guessImportDep(absBase); ok {
+		msg.Info("Importing dep configuration")
+		deps = d
+		if ign, err := dep.Ignored(absBase); err == nil {
+			config.Ignore = append(config.Ignore, ign...)
+		}
 	}
 
 	for _, i := range deps {
@@ -243,3 +254,21 @@ func guessImportGB(dir string) ([]*cfg.Dependency, bool) {
 
 	return d, true
 }
+
+func guessImportDep(dir string) ([]*cfg.Dependency, bool) {
+	d, err := dep.Parse(dir)
+	if err != nil || len(d) == 0 {
+		return []*cfg.Dependency{}, false
+	}
+
+	return d, true
+}
+
+func guessImportGovendor(dir string) ([]*cfg.Dependency, bool) {
+	d, err := govendor.Parse(dir)
+	if err != nil || len(d) == 0 {
+		return []*cfg.Dependency{}, false
+	}
+
+	return d, true
+}
@@ -1,7 +1,12 @@
 package action
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/glide/cache"
 	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/glide/repo"
 )
 
 // Debug sets the debugging flags across components.
@@ -18,3 +23,51 @@ func Quiet(on bool) {
 func NoColor(on bool) {
 	msg.Default.NoColor = on
 }
+
+// Offline sets the offline flag so only the cache is used.
+func Offline(on bool) {
+	repo.SetOffline(on)
+}
+
+// Shallow sets whether Git repositories are cloned shallowly into the cache.
+func Shallow(on bool) {
+	repo.SetShallow(on)
+}
+
+// Retries sets the number of retries for transient fetch failures and the
+// wait before the first one.
+func Retries(count int, backoff time.Duration) error {
+	if count < 0 || backoff < 0 {
+		return fmt.Errorf("invalid retries: --retries and --retry-backoff can not be negative")
+	}
+	repo.SetRetries(count, backoff)
+	return nil
+}
+
+// LockTimeout sets how long to wait on cache locks held by other instances of
+// Glide. Zero waits forever.
+func LockTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("invalid lock timeout: --lock-timeout can not be negative")
+	}
+	cache.LockTimeout = timeout
+	return nil
+}
+
+// Shutdown displays the summary of retried operations.
+func Shutdown() {
+	repo.LogRetrySummary()
+}
+
+// Output sets the format of log messages. It is one of text or json.
+func Output(format string) error {
+	switch format {
+	case textFormat:
+		msg.Default.Formatter = nil
+	case jsonFormat:
+		msg.Default.Formatter = &msg.JSONFormatter{}
+	default:
+		return fmt.Errorf("invalid output format %q: must be one of: text|json", format)
+	}
+	return nil
+}
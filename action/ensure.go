@@ -12,6 +12,8 @@ import (
 	"github.com/Masterminds/glide/mirrors"
 	"github.com/Masterminds/glide/msg"
 	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/proxy"
+	"github.com/Masterminds/glide/repo"
 	"github.com/Masterminds/glide/util"
 )
 
@@ -62,6 +64,15 @@ func EnsureConfig() *cfg.Config {
 		msg.Err("Unable to load mirrors: %s", err)
 	}
 
+	err = proxy.Load()
+	if err != nil {
+		msg.Err("Unable to load the proxy configuration: %s", err)
+	}
+
+	configConcurrency(conf.Concurrency)
+	repo.SetShallowConfig(conf)
+	repo.SetOfflineConfig(conf)
+
 	return conf
 }
 
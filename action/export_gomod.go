@@ -0,0 +1,289 @@
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/gomod"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/repo"
+	"github.com/Masterminds/semver"
+	"github.com/Masterminds/vcs"
+)
+
+// ExportGomod writes a go.mod file from the glide.yaml and glide.lock files.
+//
+// Params:
+//   - modulesTxt (bool): Also write a vendor/modules.txt file
+//   - force (bool): Overwrite an existing go.mod file
+//
+// The locked revisions are written as the semantic version tagged on them, or
+// as a pseudo-version when there is no tag. Dependencies fetched from another
+// repository, through the repo setting or a mirror, become replace directives.
+// Settings that have no equivalent in go.mod are reported.
+func ExportGomod(modulesTxt, force bool) {
+	base := "."
+	conf := EnsureConfig()
+
+	if !gpath.HasLock(base) {
+		msg.Die("Lock file (glide.lock) does not exist. Run 'glide up' to create one.")
+	}
+	lock, err := cfg.ReadLockFile(filepath.Join(base, gpath.LockFile))
+	if err != nil {
+		msg.Die("Could not load lockfile: %s", err)
+	}
+	hash, err := conf.Hash()
+	if err == nil && hash != lock.Hash {
+		msg.Warn("Lock file may be out of date. Hash check of YAML failed. You may need to run 'update'")
+	}
+
+	dest := filepath.Join(base, "go.mod")
+	if _, err := os.Stat(dest); err == nil && !force {
+		msg.Die("%s already exists. Use --force to overwrite it.", dest)
+	}
+
+	systemLock()
+
+	locks := make(cfg.Locks, 0, len(lock.Imports)+len(lock.DevImports))
+	locks = append(locks, lock.Imports...)
+	locks = append(locks, lock.DevImports...)
+	sort.Sort(locks)
+
+	mf := &gomod.File{Module: conf.Name}
+	var problems []string
+	var vendored []*cfg.Lock
+	for _, l := range locks {
+		d := cfg.DependencyFromLock(l)
+		if len(d.Os) > 0 || len(d.Arch) > 0 {
+			problems = append(problems, fmt.Sprintf("%s is limited to os %v and arch %v which go.mod can not express. It is required on all platforms", l.Name, d.Os, d.Arch))
+		}
+
+		if d.Archive != "" {
+			problems = append(problems, fmt.Sprintf("Skipping %s: the archive %s can not be used by go.mod", l.Name, d.Archive))
+			continue
+		}
+
+		// Local dependencies have no version. Go uses a zero pseudo-version
+		// for modules replaced with a directory.
+		if d.Path != "" {
+			mf.Require = append(mf.Require, gomod.Module{Path: l.Name, Version: "v0.0.0-00010101000000-000000000000"})
+			mf.Replace = append(mf.Replace, gomod.Replace{
+				Old: gomod.Module{Path: l.Name},
+				New: gomod.Module{Path: localModulePath(d.Path)},
+			})
+			vendored = append(vendored, l)
+			continue
+		}
+
+		ver, err := moduleVersion(d, l.Version)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Skipping %s: %s", l.Name, err))
+			continue
+		}
+		mf.Require = append(mf.Require, gomod.Module{Path: l.Name, Version: ver})
+		vendored = append(vendored, l)
+
+		if remote := d.Remote(); remote != "https://"+l.Name {
+			p, err := gomod.ModulePath(remote)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("Unable to replace %s with %s: %s", l.Name, remote, err))
+				continue
+			}
+			mf.Replace = append(mf.Replace, gomod.Replace{
+				Old: gomod.Module{Path: l.Name, Version: ver},
+				New: gomod.Module{Path: p, Version: ver},
+			})
+		}
+	}
+
+	for _, i := range conf.Ignore {
+		problems = append(problems, fmt.Sprintf("Ignored package %s has no equivalent in go.mod", i))
+	}
+
+	msg.Info("Writing %s", dest)
+	if err := ioutil.WriteFile(dest, mf.Format(), 0666); err != nil {
+		msg.Die("Failed to write %s: %s", dest, err)
+	}
+
+	if modulesTxt {
+		vdir, err := gpath.Vendor()
+		if err != nil {
+			msg.Die("Unable to find the vendor directory: %s", err)
+		}
+		mt := filepath.Join(vdir, "modules.txt")
+		msg.Info("Writing %s", mt)
+		if err := ioutil.WriteFile(mt, modulesText(mf, vendored, vdir), 0666); err != nil {
+			msg.Die("Failed to write %s: %s", mt, err)
+		}
+	}
+
+	for _, p := range problems {
+		msg.Warn("%s", p)
+	}
+	if len(problems) > 0 {
+		msg.Warn("%d setting(s) could not be translated to go.mod", len(problems))
+	}
+}
+
+// moduleVersion returns the module version for a locked revision from the
+// cached repository of the dependency, or the version recorded for a snapshot
+// from a proxy.
+func moduleVersion(d *cfg.Dependency, rev string) (string, error) {
+	if d.Path != "" || d.Archive != "" {
+		return "", fmt.Errorf("%s has no repository to read the version from", d.Name)
+	}
+
+	key, err := cache.Key(d.Remote())
+	if err != nil {
+		return "", err
+	}
+	if err := cache.Lock(key); err != nil {
+		return "", err
+	}
+	defer cache.Unlock(key)
+
+	loc := filepath.Join(cache.Location(), "src", key)
+	if _, err := os.Stat(loc); os.IsNotExist(err) {
+		if err := repo.VcsGet(d); err != nil {
+			return "", err
+		}
+	}
+	if info, ok, err := repo.SnapshotInfo(d.Name, loc, rev); ok {
+		if err != nil {
+			return "", err
+		}
+		if _, err := semver.NewVersion(info.Version); err == nil {
+			return gomod.TagVersion(d.Name, info.Version), nil
+		}
+		if info.Time.IsZero() {
+			return "", fmt.Errorf("the proxy did not report the time of revision %s", rev)
+		}
+		return gomod.PseudoVersion(d.Name, info.Time, info.Revision), nil
+	}
+
+	r, err := d.GetRepo(loc)
+	if err != nil {
+		return "", err
+	}
+
+	commit, t, err := commitTime(r, rev)
+	if err != nil && !cache.Offline {
+		// The cache may be older than the lock file.
+		if uerr := r.Update(); uerr == nil {
+			commit, t, err = commitTime(r, rev)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var tag *semver.Version
+	tags, _ := r.TagsFromCommit(commit)
+	for _, tg := range tags {
+		if v, err := semver.NewVersion(tg); err == nil && (tag == nil || v.GreaterThan(tag)) {
+			tag = v
+		}
+	}
+	if tag != nil {
+		return gomod.TagVersion(d.Name, tag.Original()), nil
+	}
+
+	return gomod.PseudoVersion(d.Name, t, commit), nil
+}
+
+// commitTime returns the full id and commit time of a revision, which are used
+// for pseudo-versions. Go modules only support pseudo-versions from Git and
+// Mercurial repositories.
+func commitTime(r vcs.Repo, rev string) (string, time.Time, error) {
+	var out []byte
+	var err error
+	switch r.Vcs() {
+	case vcs.Git:
+		out, err = r.RunFromDir("git", "show", "-s", "--format=%H %ct", rev)
+	case vcs.Hg:
+		out, err = r.RunFromDir("hg", "log", "-r", rev, "--template", "{node} {date|hgdate}")
+	default:
+		return "", time.Time{}, fmt.Errorf("%s repositories can not be versioned by go.mod", r.Vcs())
+	}
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("unable to find revision %s: %s", rev, strings.TrimSpace(string(out)))
+	}
+
+	f := strings.Fields(string(out))
+	if len(f) < 2 {
+		return "", time.Time{}, fmt.Errorf("unable to read the commit time of %s", rev)
+	}
+	sec, err := strconv.ParseInt(f[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("unable to read the commit time of %s: %s", rev, err)
+	}
+
+	return f[0], time.Unix(sec, 0), nil
+}
+
+// localModulePath returns a local path in the form go.mod expects, which
+// needs a ./ or ../ prefix for relative paths.
+func localModulePath(p string) string {
+	p = filepath.ToSlash(p)
+	if filepath.IsAbs(p) || strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") {
+		return p
+	}
+
+	return "./" + p
+}
+
+// modulesText returns the content of a vendor/modules.txt file listing the
+// vendored packages of each required module.
+func modulesText(mf *gomod.File, locks []*cfg.Lock, vdir string) []byte {
+	repl := map[string]gomod.Module{}
+	for _, r := range mf.Replace {
+		repl[r.Old.Path] = r.New
+	}
+
+	var b bytes.Buffer
+	for i, m := range mf.Require {
+		l := locks[i]
+		line := "# " + m.Path + " " + m.Version
+		if n, ok := repl[m.Path]; ok {
+			line += " => " + strings.TrimSpace(n.Path+" "+n.Version)
+		}
+		b.WriteString(line + "\n## explicit\n")
+
+		if hasGoFiles(filepath.Join(vdir, filepath.FromSlash(l.Name))) {
+			b.WriteString(l.Name + "\n")
+		}
+		subs := append([]string{}, l.Subpackages...)
+		sort.Strings(subs)
+		for _, s := range subs {
+			if s != "." && s != "" {
+				b.WriteString(l.Name + "/" + s + "\n")
+			}
+		}
+	}
+
+	return b.Bytes()
+}
+
+func hasGoFiles(dir string) bool {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".go") && !strings.HasSuffix(f.Name(), "_test.go") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,84 @@
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/gomod"
+)
+
+func TestModulesText(t *testing.T) {
+	vdir, err := ioutil.TempDir("", "glide-modules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(vdir)
+
+	if err := os.MkdirAll(filepath.Join(vdir, "github.com", "example", "foo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(vdir, "github.com", "example", "foo", "foo.go"), []byte("package foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mf := &gomod.File{
+		Module: "github.com/example/app",
+		Require: []gomod.Module{
+			{Path: "github.com/example/bar", Version: "v0.0.0-20170102030405-abcdef123456"},
+			{Path: "github.com/example/foo", Version: "v1.2.0"},
+		},
+		Replace: []gomod.Replace{
+			{
+				Old: gomod.Module{Path: "github.com/example/bar", Version: "v0.0.0-20170102030405-abcdef123456"},
+				New: gomod.Module{Path: "github.com/fork/bar", Version: "v0.0.0-20170102030405-abcdef123456"},
+			},
+		},
+	}
+	locks := []*cfg.Lock{
+		{Name: "github.com/example/bar", Subpackages: []string{"b", "a"}},
+		{Name: "github.com/example/foo"},
+	}
+
+	expected := `# github.com/example/bar v0.0.0-20170102030405-abcdef123456 => github.com/fork/bar v0.0.0-20170102030405-abcdef123456
+## explicit
+github.com/example/bar/a
+github.com/example/bar/b
+# github.com/example/foo v1.2.0
+## explicit
+github.com/example/foo
+`
+	if out := string(modulesText(mf, locks, vdir)); out != expected {
+		t.Errorf("Unexpected modules.txt:\n%s", out)
+	}
+}
+
+func TestLocalModulePath(t *testing.T) {
+	tests := map[string]string{
+		"../lib":     "../lib",
+		"./lib":      "./lib",
+		"lib":        "./lib",
+		"libs/foo":   "./libs/foo",
+		"/src/lib":   "/src/lib",
+		"../../libs": "../../libs",
+	}
+	for in, want := range tests {
+		if got := localModulePath(in); got != want {
+			t.Errorf("Expected %s for %s but got %s", want, in, got)
+		}
+	}
+}
+
+func TestModuleVersionLocal(t *testing.T) {
+	deps := []*cfg.Dependency{
+		{Name: "example.com/local", Path: "../local"},
+		{Name: "example.com/archive", Archive: "https://example.com/archive.tgz"},
+	}
+	for _, d := range deps {
+		if _, err := moduleVersion(d, "abc123"); err == nil {
+			t.Errorf("Expected an error for %s which has no repository", d.Name)
+		}
+	}
+}
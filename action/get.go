@@ -19,7 +19,7 @@ import (
 //
 // This includes resolving dependency resolution and re-generating the lock file.
 func Get(names []string, installer *repo.Installer, insecure, skipRecursive, stripVendor, nonInteract, testDeps bool) {
-	cache.SystemLock()
+	systemLock()
 
 	base := gpath.Basepath()
 	EnsureGopath()
@@ -38,18 +38,20 @@ func Get(names []string, installer *repo.Installer, insecure, skipRecursive, str
 		return
 	}
 
+	// Prior to resolving dependencies we need to start working with a clone
+	// of the conf because we'll be making real changes to it. The overrides
+	// are applied to the clone as well.
+	confcopy := conf.Clone()
+	repo.ApplyOverrides(confcopy)
+
 	// Fetch the new packages. Can't resolve versions via installer.Update if
 	// get is called while the vendor/ directory is empty so we checkout
 	// everything.
-	err = installer.Checkout(conf)
+	err = installer.Checkout(confcopy)
 	if err != nil {
 		msg.Die("Failed to checkout packages: %s", err)
 	}
 
-	// Prior to resolving dependencies we need to start working with a clone
-	// of the conf because we'll be making real changes to it.
-	confcopy := conf.Clone()
-
 	if !skipRecursive {
 		// Get all repos and update them.
 		// TODO: Can we streamline this in any way? The reason that we update all
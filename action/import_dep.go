@@ -0,0 +1,30 @@
+package action
+
+import (
+	"github.com/Masterminds/glide/dep"
+	"github.com/Masterminds/glide/msg"
+)
+
+// ImportDep imports dep's Gopkg.toml and Gopkg.lock files.
+func ImportDep(dest string) {
+	base := "."
+	config := EnsureConfig()
+	if !dep.Has(base) {
+		msg.Die("There is no dep configuration to import.")
+	}
+	deps, err := dep.Parse(base)
+	if err != nil {
+		msg.Die("Failed to extract dep configuration: %s", err)
+	}
+	ign, err := dep.Ignored(base)
+	if err != nil {
+		msg.Die("Failed to extract dep configuration: %s", err)
+	}
+	for _, i := range ign {
+		if !config.HasIgnore(i) {
+			config.Ignore = append(config.Ignore, i)
+		}
+	}
+	appendImports(deps, config)
+	writeConfigToFileOrStdout(config, dest)
+}
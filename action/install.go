@@ -2,8 +2,8 @@ package action
 
 import (
 	"path/filepath"
+	"strings"
 
-	"github.com/Masterminds/glide/cache"
 	"github.com/Masterminds/glide/cfg"
 	"github.com/Masterminds/glide/msg"
 	gpath "github.com/Masterminds/glide/path"
@@ -11,8 +11,11 @@ import (
 )
 
 // Install installs a vendor directory based on an existing Glide configuration.
-func Install(installer *repo.Installer, stripVendor bool) {
-	cache.SystemLock()
+//
+// When noLocal is true a glide.lock file with dependencies using a local path
+// is rejected.
+func Install(installer *repo.Installer, stripVendor, noLocal bool) {
+	systemLock()
 
 	base := "."
 	// Ensure GOPATH
@@ -23,7 +26,7 @@ func Install(installer *repo.Installer, stripVendor bool) {
 	// Lockfile exists
 	if !gpath.HasLock(base) {
 		msg.Info("Lock file (glide.lock) does not exist. Performing update.")
-		Update(installer, false, stripVendor)
+		Update(installer, false, stripVendor, false, nil)
 		return
 	}
 	// Load lockfile
@@ -31,6 +34,9 @@ func Install(installer *repo.Installer, stripVendor bool) {
 	if err != nil {
 		msg.Die("Could not load lockfile.")
 	}
+	if local := lock.Local(); noLocal && len(local) > 0 {
+		msg.Die("Lock file has dependencies using a local path: %s", strings.Join(local, ", "))
+	}
 	// Verify lockfile hasn't changed
 	hash, err := conf.Hash()
 	if err != nil {
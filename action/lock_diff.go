@@ -0,0 +1,98 @@
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/glide/repo"
+)
+
+// LockDiff displays the changes to dependencies between two lock files.
+//
+// Params:
+//   - oldFile (string): The path to the old lock file
+//   - newFile (string): The path to the new lock file
+//   - format (string): The format to output (text, json, json-pretty)
+//   - showLog (bool): Display the commits between the old and new versions
+func LockDiff(oldFile, newFile, format string, showLog bool) {
+	if format != textFormat && format != jsonFormat && format != jsonPrettyFormat {
+		msg.Die("invalid output format: must be one of: json|json-pretty|text")
+	}
+
+	old, err := cfg.ReadLockFile(oldFile)
+	if err != nil {
+		msg.Die("Could not load lock file %s: %s", oldFile, err)
+	}
+	new, err := cfg.ReadLockFile(newFile)
+	if err != nil {
+		msg.Die("Could not load lock file %s: %s", newFile, err)
+	}
+
+	changes := repo.DiffLocks(old, new)
+	switch format {
+	case textFormat:
+		for _, c := range changes {
+			msg.Puts("%s", lockChangeLine(c))
+		}
+	case jsonFormat:
+		json.NewEncoder(msg.Default.Stdout).Encode(changes)
+	case jsonPrettyFormat:
+		b, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			msg.Die("could not marshal lock changes: %s", err)
+		}
+		msg.Puts("%s", b)
+	}
+
+	if showLog {
+		for _, c := range changes {
+			repo.DisplayLockChangeLog(c)
+		}
+	}
+}
+
+// displayLockChanges displays a summary of the changes to the lock file.
+func displayLockChanges(changes []*repo.LockChange, showLog bool) {
+	if len(changes) == 0 {
+		return
+	}
+
+	msg.Info("Changes to glide.lock:")
+	for _, c := range changes {
+		msg.Info("--> %s", lockChangeLine(c))
+		if showLog {
+			repo.DisplayLockChangeLog(c)
+		}
+	}
+}
+
+func lockChangeLine(c *repo.LockChange) string {
+	oldv := describeVersion(c.OldVersion, c.OldTag)
+	newv := describeVersion(c.NewVersion, c.NewTag)
+
+	switch c.Change {
+	case repo.LockAdded:
+		return fmt.Sprintf("Added %s at %s", c.Name, newv)
+	case repo.LockRemoved:
+		return fmt.Sprintf("Removed %s at %s", c.Name, oldv)
+	case repo.LockUpgraded:
+		return fmt.Sprintf("Upgraded %s from %s to %s", c.Name, oldv, newv)
+	case repo.LockDowngraded:
+		return fmt.Sprintf("Downgraded %s from %s to %s", c.Name, oldv, newv)
+	case repo.LockRepoChanged:
+		return fmt.Sprintf("Changed the repository of %s from %s at %s to %s at %s", c.Name, c.OldRepo, oldv, c.NewRepo, newv)
+	}
+
+	return fmt.Sprintf("Changed %s from %s to %s", c.Name, oldv, newv)
+}
+
+// describeVersion returns a locked version with its tag when it has one.
+func describeVersion(version, tag string) string {
+	if tag != "" {
+		return tag + " (" + shortRev(version) + ")"
+	}
+
+	return orDash(shortRev(version))
+}
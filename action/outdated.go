@@ -0,0 +1,137 @@
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/repo"
+)
+
+// Outdated reports the locked, wanted and latest versions of each dependency.
+//
+// Params:
+//   - format (string): The format to output (text, json, json-pretty)
+//
+// Returns true when any dependency is outdated. An error is returned when the
+// versions of any dependency could not be checked.
+func Outdated(format string) (bool, error) {
+	if format != textFormat && format != jsonFormat && format != jsonPrettyFormat {
+		msg.Die("invalid output format: must be one of: json|json-pretty|text")
+	}
+
+	base := "."
+	systemLock()
+	conf := EnsureConfig()
+
+	if !gpath.HasLock(base) {
+		msg.Die("Lock file (glide.lock) does not exist. Run 'glide up' to create one.")
+	}
+	lock, err := cfg.ReadLockFile(filepath.Join(base, gpath.LockFile))
+	if err != nil {
+		msg.Die("Could not load lockfile: %s", err)
+	}
+
+	locks := append(cfg.Locks{}, lock.Imports...)
+	locks = append(locks, lock.DevImports...)
+	locked := make(map[string]string, len(locks))
+	for _, l := range locks {
+		locked[l.Name] = l.Version
+	}
+
+	deps := append(cfg.Dependencies{}, conf.Imports...)
+	deps = append(deps, conf.DevImports...)
+
+	// Transitive dependencies are only in the lock file. They have no
+	// version constraint in the glide.yaml file.
+	for _, l := range locks {
+		if deps.Has(l.Name) {
+			continue
+		}
+		d := cfg.DependencyFromLock(l)
+		d.Reference = ""
+		d.Pin = ""
+		deps = append(deps, d)
+	}
+
+	versions := make([]*repo.VersionInfo, 0, len(deps))
+	outdated := false
+	failed := 0
+	for _, dep := range deps {
+		if conf.HasIgnore(dep.Name) {
+			continue
+		}
+		if dep.Path != "" {
+			msg.Info("--> Skipping %s which uses the local path %s", dep.Name, dep.Path)
+			continue
+		}
+		if dep.Archive != "" {
+			msg.Info("--> Skipping %s which is downloaded from %s", dep.Name, dep.Archive)
+			continue
+		}
+		msg.Info("--> Checking versions of %s", dep.Name)
+		vi, err := repo.DependencyVersions(dep, locked[dep.Name])
+		if err != nil {
+			msg.Err("Unable to check the versions of %s: %s", dep.Name, err)
+			failed++
+			continue
+		}
+		versions = append(versions, vi)
+		outdated = outdated || vi.Outdated
+	}
+
+	outputVersions(versions, format)
+
+	if failed > 0 {
+		return outdated, fmt.Errorf("Unable to check the versions of %d dependencies", failed)
+	}
+	return outdated, nil
+}
+
+func outputVersions(versions []*repo.VersionInfo, format string) {
+	switch format {
+	case textFormat:
+		w := tabwriter.NewWriter(msg.Default.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "PACKAGE\tLOCKED\tWANTED\tLATEST\tOUTDATED")
+		for _, v := range versions {
+			locked := v.LockedTag
+			if locked == "" {
+				locked = shortRev(v.Locked)
+			}
+			o := "no"
+			if v.NotLocked {
+				o = "not locked"
+			} else if v.Outdated {
+				o = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", v.Name, orDash(locked), orDash(v.Wanted), orDash(v.Latest), o)
+		}
+		w.Flush()
+	case jsonFormat:
+		json.NewEncoder(msg.Default.Stdout).Encode(versions)
+	case jsonPrettyFormat:
+		b, err := json.MarshalIndent(versions, "", "  ")
+		if err != nil {
+			msg.Die("could not marshal versions: %s", err)
+		}
+		msg.Puts("%s", b)
+	}
+}
+
+func shortRev(rev string) string {
+	if len(rev) > 12 {
+		return rev[:12]
+	}
+	return rev
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
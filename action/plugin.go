@@ -53,7 +53,7 @@ func Plugin(command string, args []string) {
 	}
 
 	if _, err := proc.Wait(); err != nil {
-		msg.Err(err.Error())
+		msg.Err("%s", err)
 		os.Exit(1)
 	}
 }
@@ -1,7 +1,6 @@
 package action
 
 import (
-	"github.com/Masterminds/glide/cache"
 	"github.com/Masterminds/glide/cfg"
 	"github.com/Masterminds/glide/msg"
 	gpath "github.com/Masterminds/glide/path"
@@ -10,7 +9,7 @@ import (
 
 // Remove removes a dependncy from the configuration.
 func Remove(packages []string, inst *repo.Installer) {
-	cache.SystemLock()
+	systemLock()
 	base := gpath.Basepath()
 	EnsureGopath()
 	EnsureVendorDir()
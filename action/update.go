@@ -3,38 +3,49 @@ package action
 import (
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 
-	"github.com/Masterminds/glide/cache"
 	"github.com/Masterminds/glide/cfg"
 	"github.com/Masterminds/glide/msg"
 	gpath "github.com/Masterminds/glide/path"
 	"github.com/Masterminds/glide/repo"
 )
 
-// Update updates repos and the lock file from the main glide yaml.
-func Update(installer *repo.Installer, skipRecursive, stripVendor bool) {
-	cache.SystemLock()
+// Update updates repos and the lock file from the main glide yaml. The changes
+// to the lock file are displayed and, when showLog is true, the commits
+// between the old and new versions of Git dependencies.
+//
+// When packages are named only they are updated. The other dependencies are
+// kept at their versions in the lock file.
+func Update(installer *repo.Installer, skipRecursive, stripVendor, showLog bool, packages []string) {
+	systemLock()
 
 	base := "."
 	EnsureGopath()
 	EnsureVendorDir()
 	conf := EnsureConfig()
 
+	if len(packages) > 0 {
+		keepLocked(installer, conf, base, skipRecursive, packages)
+	}
+
+	// Prior to resolving dependencies we need to start working with a clone
+	// of the conf because we'll be making real changes to it. The overrides
+	// are applied to the clone as well.
+	confcopy := conf.Clone()
+	repo.ApplyOverrides(confcopy)
+
 	// Try to check out the initial dependencies.
-	if err := installer.Checkout(conf); err != nil {
+	if err := installer.Checkout(confcopy); err != nil {
 		msg.Die("Failed to do initial checkout of config: %s", err)
 	}
 
 	// Set the versions for the initial dependencies so that resolved dependencies
 	// are rooted in the correct version of the base.
-	if err := repo.SetReference(conf, installer.ResolveTest); err != nil {
+	if err := repo.SetReference(confcopy, installer.ResolveTest); err != nil {
 		msg.Die("Failed to set initial config references: %s", err)
 	}
 
-	// Prior to resolving dependencies we need to start working with a clone
-	// of the conf because we'll be making real changes to it.
-	confcopy := conf.Clone()
-
 	if !skipRecursive {
 		// Get all repos and update them.
 		err := installer.Update(confcopy)
@@ -76,11 +87,13 @@ func Update(installer *repo.Installer, skipRecursive, stripVendor bool) {
 			msg.Die("Failed to generate lock file: %s", err)
 		}
 		wl := true
+		var prev *cfg.Lockfile
 		if gpath.HasLock(base) {
 			yml, err := ioutil.ReadFile(filepath.Join(base, gpath.LockFile))
 			if err == nil {
 				l2, err := cfg.LockfileFromYaml(yml)
 				if err == nil {
+					prev = l2
 					f1, err := l2.Fingerprint()
 					f2, err2 := lock.Fingerprint()
 					if err == nil && err2 == nil && f1 == f2 {
@@ -94,6 +107,9 @@ func Update(installer *repo.Installer, skipRecursive, stripVendor bool) {
 				msg.Err("Could not write lock file to %s: %s", base, err)
 				return
 			}
+			if prev != nil {
+				displayLockChanges(repo.DiffLocks(prev, lock), showLog)
+			}
 		} else {
 			msg.Info("Versions did not change. Skipping glide.lock update.")
 		}
@@ -111,3 +127,42 @@ func Update(installer *repo.Installer, skipRecursive, stripVendor bool) {
 		}
 	}
 }
+
+// keepLocked keeps the dependencies in the lock file other than the named
+// packages at their locked versions.
+func keepLocked(installer *repo.Installer, conf *cfg.Config, base string, skipRecursive bool, packages []string) {
+	if skipRecursive {
+		msg.Die("Selected packages can not be updated with --no-recursive because the lock file is not updated")
+	}
+	if !gpath.HasLock(base) {
+		msg.Die("Lock file (glide.lock) does not exist. Run 'glide up' without package names to create one.")
+	}
+	lock, err := cfg.ReadLockFile(filepath.Join(base, gpath.LockFile))
+	if err != nil {
+		msg.Die("Could not load lockfile: %s", err)
+	}
+
+	deps := append(cfg.Dependencies{}, conf.Imports...)
+	deps = append(deps, conf.DevImports...)
+	names := make([]string, 0, len(packages))
+	for _, p := range packages {
+		name := ""
+		for _, l := range append(append(cfg.Locks{}, lock.Imports...), lock.DevImports...) {
+			if p == l.Name || strings.HasPrefix(p, l.Name+"/") {
+				name = l.Name
+			}
+		}
+		for _, d := range deps {
+			if p == d.Name || strings.HasPrefix(p, d.Name+"/") {
+				name = d.Name
+			}
+		}
+		if name == "" {
+			msg.Die("%s is not a dependency in glide.yaml or glide.lock", p)
+		}
+		names = append(names, name)
+	}
+
+	msg.Info("Updating %s. Other dependencies are kept at their versions in glide.lock.", strings.Join(names, ", "))
+	installer.KeepLocked(lock, names)
+}
@@ -0,0 +1,202 @@
+package action
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/repo"
+	"github.com/Masterminds/vcs"
+)
+
+// Verify compares the vendor/ directory to the glide.lock file.
+//
+// It does not change anything on disk. Each difference found is reported as
+// an error and the application exits non-zero when there are any. When noLocal
+// is true dependencies using a local path are reported as well.
+func Verify(noLocal bool) {
+	base := "."
+	conf := EnsureConfig()
+
+	if !gpath.HasLock(base) {
+		msg.Die("Lock file (glide.lock) does not exist. Nothing to verify.")
+	}
+	lock, err := cfg.ReadLockFile(filepath.Join(base, gpath.LockFile))
+	if err != nil {
+		msg.Die("Could not load lockfile: %s", err)
+	}
+
+	hash, err := conf.Hash()
+	if err == nil && hash != lock.Hash {
+		msg.Warn("Lock file may be out of date. Hash check of YAML failed. You may need to run 'update'")
+	}
+
+	vdir, err := gpath.Vendor()
+	if err != nil {
+		msg.Die("Unable to find the vendor directory: %s", err)
+	}
+
+	msg.Info("Verifying %s against %s", gpath.VendorDir, gpath.LockFile)
+	problems := verifyVendor(lock, vdir)
+	if noLocal {
+		for _, n := range lock.Local() {
+			problems = append(problems, fmt.Sprintf("%s uses a local path", n))
+		}
+	}
+	for _, p := range problems {
+		msg.Err("%s", p)
+	}
+
+	if len(problems) > 0 {
+		msg.Die("Found %d difference(s) between %s and %s", len(problems), gpath.VendorDir, gpath.LockFile)
+	}
+
+	msg.Info("%s matches %s", gpath.VendorDir, gpath.LockFile)
+}
+
+// verifyVendor returns a description of each difference between the locked
+// dependencies and the contents of the vendor directory at vdir.
+func verifyVendor(lock *cfg.Lockfile, vdir string) []string {
+	var problems []string
+
+	locks := make(cfg.Locks, 0, len(lock.Imports)+len(lock.DevImports))
+	locks = append(locks, lock.Imports...)
+	locks = append(locks, lock.DevImports...)
+	sort.Sort(locks)
+
+	for _, l := range locks {
+		problems = append(problems, verifyLock(l, vdir)...)
+	}
+
+	extra, err := extraVendorPackages(locks, vdir)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("Unable to scan %s for extra packages: %s", vdir, err))
+	}
+	for _, e := range extra {
+		problems = append(problems, fmt.Sprintf("Extra package %s is not in the lock file", e))
+	}
+
+	return problems
+}
+
+func verifyLock(l *cfg.Lock, vdir string) []string {
+	pth := filepath.Join(vdir, filepath.FromSlash(l.Name))
+	if fi, err := os.Stat(pth); err != nil || !fi.IsDir() {
+		return []string{fmt.Sprintf("Missing package %s", l.Name)}
+	}
+
+	var problems []string
+	for _, sp := range l.Subpackages {
+		if sp == "." || sp == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(pth, filepath.FromSlash(sp))); err != nil {
+			problems = append(problems, fmt.Sprintf("Missing subpackage %s/%s", l.Name, sp))
+		}
+	}
+
+	// The revision and dirty checks are only possible when the VCS metadata
+	// was kept in the vendor/ directory.
+	r, err := vendorRepo(pth)
+	if err != nil {
+		r = nil
+	} else {
+		ver, err := r.Version()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Unable to read the revision of %s: %s", l.Name, err))
+		} else if ver != l.Version {
+			problems = append(problems, fmt.Sprintf("Revision mismatch for %s: locked %s, found %s", l.Name, l.Version, ver))
+		}
+	}
+
+	if l.Hash != "" {
+		h, err := repo.TreeHash(pth)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Unable to hash %s: %s", l.Name, err))
+		} else if h != l.Hash {
+			problems = append(problems, fmt.Sprintf("Local modifications in %s: contents do not match the locked hash", l.Name))
+		}
+	} else if r != nil {
+		if r.IsDirty() {
+			problems = append(problems, fmt.Sprintf("Local modifications in %s", l.Name))
+		}
+	} else {
+		msg.Debug("No hash or VCS metadata for %s. Unable to check for local modifications", l.Name)
+	}
+
+	return problems
+}
+
+// vendorRepo returns a repo for a package in vendor/ when it contains VCS
+// metadata.
+func vendorRepo(pth string) (vcs.Repo, error) {
+	t, err := vcs.DetectVcsFromFS(pth)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case vcs.Git:
+		return vcs.NewGitRepo("", pth)
+	case vcs.Svn:
+		return vcs.NewSvnRepo("", pth)
+	case vcs.Hg:
+		return vcs.NewHgRepo("", pth)
+	case vcs.Bzr:
+		return vcs.NewBzrRepo("", pth)
+	}
+
+	return nil, vcs.ErrCannotDetectVCS
+}
+
+// extraVendorPackages walks the vendor directory and returns the directories
+// that are neither a locked package nor a parent of one.
+func extraVendorPackages(locks cfg.Locks, vdir string) ([]string, error) {
+	names := make(map[string]bool, len(locks))
+	for _, l := range locks {
+		names[l.Name] = true
+	}
+
+	var extra []string
+	err := filepath.Walk(vdir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == vdir || !fi.IsDir() {
+			return nil
+		}
+
+		// A vendor/.git directory is preserved when vendor/ is a submodule.
+		if strings.HasPrefix(fi.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(vdir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if names[rel] {
+			return filepath.SkipDir
+		}
+		for n := range names {
+			if strings.HasPrefix(n, rel+"/") {
+				return nil
+			}
+		}
+
+		extra = append(extra, rel)
+		return filepath.SkipDir
+	})
+	if err != nil && os.IsNotExist(err) {
+		return extra, nil
+	}
+
+	return extra, err
+}
@@ -0,0 +1,70 @@
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/repo"
+)
+
+func TestVerifyVendor(t *testing.T) {
+	vdir, err := ioutil.TempDir("", "glide-verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(vdir)
+
+	write := func(p, c string) {
+		fp := filepath.Join(vdir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fp, []byte(c), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("github.com/example/foo/foo.go", "package foo")
+	write("github.com/example/foo/bar/bar.go", "package bar")
+	write("github.com/example/baz/baz.go", "package baz")
+	write("github.com/example/extra/extra.go", "package extra")
+
+	h, err := repo.TreeHash(filepath.Join(vdir, "github.com/example/foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lock := &cfg.Lockfile{
+		Imports: cfg.Locks{
+			&cfg.Lock{Name: "github.com/example/foo", Subpackages: []string{"bar"}, Hash: h},
+			&cfg.Lock{Name: "github.com/example/baz", Subpackages: []string{"qux"}, Hash: "abc"},
+			&cfg.Lock{Name: "github.com/example/missing"},
+		},
+	}
+
+	problems := verifyVendor(lock, vdir)
+	expected := []string{
+		"Missing subpackage github.com/example/baz/qux",
+		"Local modifications in github.com/example/baz",
+		"Missing package github.com/example/missing",
+		"Extra package github.com/example/extra",
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems but got %d: %s", len(expected), len(problems), strings.Join(problems, "; "))
+	}
+	for i, e := range expected {
+		if !strings.HasPrefix(problems[i], e) {
+			t.Errorf("Expected problem %q but got %q", e, problems[i])
+		}
+	}
+
+	lock.Imports = lock.Imports[:1]
+	lock.Imports = append(lock.Imports, &cfg.Lock{Name: "github.com/example/baz"}, &cfg.Lock{Name: "github.com/example/extra"})
+	if problems := verifyVendor(lock, vdir); len(problems) != 0 {
+		t.Errorf("Expected no problems but got: %s", strings.Join(problems, "; "))
+	}
+}
@@ -0,0 +1,144 @@
+package action
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dependency"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/util"
+)
+
+// Why prints the import chains from the local packages to a package, shortest
+// first.
+//
+// The import graph is built by resolving the local packages against the
+// vendor/ directory so the dependencies need to be installed first.
+func Why(pkg string, allFiles bool) {
+	base := "."
+	conf := EnsureConfig()
+	pkg = strings.TrimSuffix(pkg, "/")
+
+	basedir, err := filepath.Abs(base)
+	if err != nil {
+		msg.Die("Could not read directory: %s", err)
+	}
+	r, err := dependency.NewResolver(basedir)
+	if err != nil {
+		msg.Die("Could not create a resolver: %s", err)
+	}
+	r.Config = conf
+	r.ResolveTest = true
+	r.ResolveAllFiles = allFiles
+	r.Handler = &whyHandler{
+		DefaultMissingPackageHandler: dependency.DefaultMissingPackageHandler{
+			Missing: []string{},
+			Gopath:  []string{},
+			Prefix:  r.VendorDir,
+		},
+	}
+	r.VersionHandler = &whyVersionHandler{}
+
+	if _, _, err = r.ResolveLocal(true); err != nil {
+		msg.Warn("Unable to resolve the complete import graph: %s", err)
+	}
+
+	chains, more := r.ImportChains(pkg, maxWhyChains)
+	if len(chains) == 0 {
+		msg.Warn("%s is not imported by %s", pkg, conf.Name)
+		return
+	}
+
+	deps := whyDependencies(conf, base)
+	msg.Puts("%s is imported through:", pkg)
+	for _, c := range chains {
+		parts := make([]string, len(c.Packages))
+		for i, p := range c.Packages {
+			parts[i] = p + whyConstraints(deps, p, conf.Name)
+		}
+		line := strings.Join(parts, " -> ")
+		if c.Test {
+			line = "(test) " + line
+		}
+		msg.Puts("  %s", line)
+	}
+	if more {
+		msg.Puts("  ... and more. Only %d chains are shown", len(chains))
+	}
+}
+
+// maxWhyChains limits the import chains printed by Why.
+const maxWhyChains = 50
+
+// whyDependencies collects the dependencies from the config and lock file
+// so their os and arch constraints can be displayed.
+func whyDependencies(conf *cfg.Config, base string) cfg.Dependencies {
+	deps := append(cfg.Dependencies{}, conf.Imports...)
+	deps = append(deps, conf.DevImports...)
+
+	if !gpath.HasLock(base) {
+		return deps
+	}
+	lock, err := cfg.ReadLockFile(filepath.Join(base, gpath.LockFile))
+	if err != nil {
+		msg.Warn("Could not load lockfile: %s", err)
+		return deps
+	}
+	for _, l := range append(lock.Imports, lock.DevImports...) {
+		if !deps.Has(l.Name) {
+			deps = append(deps, cfg.DependencyFromLock(l))
+		}
+	}
+
+	return deps
+}
+
+func whyConstraints(deps cfg.Dependencies, pkg, name string) string {
+	root, _ := util.NormalizeName(pkg)
+	if root == name {
+		return ""
+	}
+	d := deps.Get(root)
+	if d == nil {
+		return ""
+	}
+
+	var c []string
+	if len(d.Os) > 0 {
+		c = append(c, "os: "+strings.Join(d.Os, ", "))
+	}
+	if len(d.Arch) > 0 {
+		c = append(c, "arch: "+strings.Join(d.Arch, ", "))
+	}
+	if len(c) == 0 {
+		return ""
+	}
+
+	return " [" + strings.Join(c, "; ") + "]"
+}
+
+// whyHandler resolves packages from the vendor/ directory without the
+// chatter of the default handler.
+type whyHandler struct {
+	dependency.DefaultMissingPackageHandler
+}
+
+// InVendor is run when a package is found in the vendor/ folder
+func (w *whyHandler) InVendor(pkg string, addTest bool) error {
+	return nil
+}
+
+// whyVersionHandler leaves the versions in vendor/ as they are.
+type whyVersionHandler struct{}
+
+// Process is a no-op
+func (w *whyVersionHandler) Process(pkg string) error {
+	return nil
+}
+
+// SetVersion is a no-op
+func (w *whyVersionHandler) SetVersion(pkg string, testDep bool) error {
+	return nil
+}
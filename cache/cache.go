@@ -49,6 +49,10 @@ var Enabled = true
 // ErrCacheDisabled is returned with the cache is disabled.
 var ErrCacheDisabled = errors.New("Cache disabled")
 
+// Offline restricts operations to the repositories and metadata already in
+// the cache. Anything that would need the network fails instead.
+var Offline = false
+
 var isSetup bool
 
 var setupMutex sync.Mutex
@@ -145,6 +149,13 @@ func Key(repo string) (string, error) {
 type RepoInfo struct {
 	DefaultBranch string `json:"default-branch"`
 	LastUpdate    string `json:"last-update"`
+
+	// Remote is the location the repo was cloned from.
+	Remote string `json:"remote,omitempty"`
+
+	// LastUse is when the repo was last exported to a vendor directory in
+	// RFC 3339 format.
+	LastUse string `json:"last-use,omitempty"`
 }
 
 // SaveRepoData stores data about a repo in the Glide cache
@@ -152,8 +163,12 @@ func SaveRepoData(key string, data RepoInfo) error {
 	if !Enabled {
 		return ErrCacheDisabled
 	}
-	location := Location()
 	data.LastUpdate = time.Now().String()
+	return writeRepoData(key, data)
+}
+
+func writeRepoData(key string, data RepoInfo) error {
+	location := Location()
 	d, err := json.Marshal(data)
 	if err != nil {
 		return err
@@ -195,12 +210,67 @@ func RepoData(key string) (*RepoInfo, error) {
 	return c, nil
 }
 
+// Touch records that a repo in the cache was used now. When the repo was last
+// updated is kept.
+func Touch(key string) error {
+	d, err := RepoData(key)
+	if err == ErrCacheDisabled {
+		return err
+	}
+	d.LastUse = time.Now().UTC().Format(time.RFC3339)
+	return writeRepoData(key, *d)
+}
+
+// LastUse returns when a repo in the cache was last used. Repos without a
+// recorded use fall back to when their data was last saved and then to the
+// modification time of their directory.
+func LastUse(key string) time.Time {
+	if d, err := RepoData(key); err == nil {
+		if t, err := time.Parse(time.RFC3339, d.LastUse); err == nil {
+			return t
+		}
+
+		// LastUpdate is written by time.Time.String, which can end with a
+		// monotonic clock reading.
+		lu := d.LastUpdate
+		if i := strings.Index(lu, " m="); i >= 0 {
+			lu = lu[:i]
+		}
+		if t, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", lu); err == nil {
+			return t
+		}
+	}
+
+	if fi, err := os.Stat(filepath.Join(Location(), "src", key)); err == nil {
+		return fi.ModTime()
+	}
+
+	return time.Time{}
+}
+
+// Remove deletes a repo and its data from the cache.
+func Remove(key string) error {
+	location := Location()
+	if err := os.RemoveAll(filepath.Join(location, "src", key)); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(location, "info", key+".json"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
 var lockSync sync.Mutex
 
 var lockData = make(map[string]*sync.Mutex)
 
-// Lock locks a particular key name
-func Lock(name string) {
+var fileLocks = make(map[string]*fileLock)
+
+// Lock locks a particular key name. Other instances of Glide sharing the cache
+// wait until it is unlocked.
+func Lock(name string) error {
 	lockSync.Lock()
 	m, ok := lockData[name]
 	if !ok {
@@ -210,12 +280,30 @@ func Lock(name string) {
 	lockSync.Unlock()
 	msg.Debug("Locking %s", name)
 	m.Lock()
+
+	if !Enabled {
+		return nil
+	}
+	l, err := lockFile(filepath.Join(Location(), "locks", name+".lock"), true, name)
+	if err != nil {
+		m.Unlock()
+		return err
+	}
+	lockSync.Lock()
+	fileLocks[name] = l
+	lockSync.Unlock()
+
+	return nil
 }
 
 // Unlock unlocks a particular key name
 func Unlock(name string) {
 	msg.Debug("Unlocking %s", name)
 	lockSync.Lock()
+	if l, ok := fileLocks[name]; ok {
+		l.unlock()
+		delete(fileLocks, name)
+	}
 	if m, ok := lockData[name]; ok {
 		m.Unlock()
 	}
@@ -1,6 +1,12 @@
 package cache
 
-import "testing"
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	gpath "github.com/Masterminds/glide/path"
+)
 
 func TestKey(t *testing.T) {
 	tests := map[string]string{
@@ -20,3 +26,44 @@ func TestKey(t *testing.T) {
 		}
 	}
 }
+
+func TestTouch(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "glide-cache-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	home := gpath.Home()
+	gpath.SetHome(tmp)
+	SetupReset()
+	defer func() {
+		gpath.SetHome(home)
+		SetupReset()
+	}()
+
+	if err := SaveRepoData("test", RepoInfo{DefaultBranch: "master"}); err != nil {
+		t.Fatal(err)
+	}
+	before, err := RepoData("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Touch("test"); err != nil {
+		t.Fatal(err)
+	}
+	after, err := RepoData("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.LastUpdate != before.LastUpdate {
+		t.Errorf("Expected the last update %q to be kept but got %q", before.LastUpdate, after.LastUpdate)
+	}
+	if after.LastUse == "" {
+		t.Error("Expected the last use to be recorded")
+	}
+	if after.DefaultBranch != "master" {
+		t.Errorf("Expected the default branch to be kept but got %q", after.DefaultBranch)
+	}
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Masterminds/glide/msg"
+)
+
+// LockTimeout is how long to wait on a lock held by another instance of Glide
+// before giving up. Zero waits forever.
+var LockTimeout = 10 * time.Minute
+
+// errLocked is returned by tryLockFile when another process holds the lock.
+var errLocked = errors.New("locked by another process")
+
+// lockHolder is written to a lock file by the process holding it exclusively
+// so others can report who they are waiting on and detect stale locks.
+type lockHolder struct {
+	Comment string `json:"comment"`
+	Pid     int    `json:"pid"`
+	Host    string `json:"host"`
+	Time    string `json:"time"`
+}
+
+// fileLock is an advisory lock on a file held by this process. The operating
+// system releases it when the process exits, even when it crashes.
+type fileLock struct {
+	f *os.File
+}
+
+// lockFile waits for and takes an advisory lock on the file at p. Many
+// processes can hold a shared lock at the same time while an exclusive lock
+// is held by one process. The what argument describes the lock in messages.
+func lockFile(p string, exclusive bool, what string) (*fileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	wait := 50 * time.Millisecond
+	var announced bool
+	for {
+		f, err := os.OpenFile(p, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		err = tryLockFile(f, exclusive)
+		if err == nil {
+			// A stale lock file can be removed by another process between
+			// opening and locking it. The lock is only good if it is on the
+			// file that is still there.
+			fi, serr := f.Stat()
+			pi, perr := os.Stat(p)
+			if serr == nil && perr == nil && os.SameFile(fi, pi) {
+				// Anything in the file is left by an exclusive holder that
+				// has exited and is cleared by shared holders.
+				l := &fileLock{f: f}
+				if exclusive {
+					l.writeHolder()
+				} else {
+					f.Truncate(0)
+				}
+				return l, nil
+			}
+			unlockFile(f)
+			f.Close()
+			continue
+		}
+		f.Close()
+		if err != errLocked {
+			return nil, fmt.Errorf("Unable to lock %s: %s", p, err)
+		}
+
+		h := readHolder(p)
+		if h != nil && isStale(h) {
+			msg.Warn("Removing the stale lock on %s left by process %d", what, h.Pid)
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			continue
+		}
+
+		if !announced {
+			announced = true
+			if h != nil {
+				msg.Info("Waiting on %s held by process %d on %s since %s", what, h.Pid, h.Host, h.Time)
+			} else {
+				msg.Info("Waiting on %s held by another instance of Glide", what)
+			}
+		}
+
+		if LockTimeout > 0 && time.Since(start) >= LockTimeout {
+			if h != nil {
+				return nil, fmt.Errorf("Timed out after %s waiting on %s held by process %d on %s since %s. Use --lock-timeout to wait longer", LockTimeout, what, h.Pid, h.Host, h.Time)
+			}
+			return nil, fmt.Errorf("Timed out after %s waiting on %s held by another instance of Glide. Use --lock-timeout to wait longer", LockTimeout, what)
+		}
+
+		time.Sleep(wait)
+		if wait < time.Second {
+			wait *= 2
+		}
+	}
+}
+
+// unlock releases the lock. The file is left in place as removing it would
+// race with other processes opening it.
+func (l *fileLock) unlock() {
+	if l.f == nil {
+		return
+	}
+	l.f.Truncate(0)
+	unlockFile(l.f)
+	l.f.Close()
+	l.f = nil
+}
+
+func (l *fileLock) writeHolder() {
+	host, _ := os.Hostname()
+	h := &lockHolder{
+		Comment: "File managed by Glide (https://glide.sh)",
+		Pid:     os.Getpid(),
+		Host:    host,
+		Time:    time.Now().Format(time.RFC3339),
+	}
+	out, err := json.Marshal(h)
+	if err != nil {
+		return
+	}
+	if err := l.f.Truncate(0); err == nil {
+		l.f.WriteAt(out, 0)
+	}
+}
+
+func readHolder(p string) *lockHolder {
+	b, err := ioutil.ReadFile(p)
+	if err != nil || len(b) == 0 {
+		return nil
+	}
+	h := &lockHolder{}
+	if err := json.Unmarshal(b, h); err != nil || h.Pid == 0 {
+		return nil
+	}
+
+	return h
+}
+
+// isStale returns true when the process recorded as holding a lock has
+// exited. The lock of a process that exited is normally released by the
+// operating system but can linger on network file systems. Processes on other
+// hosts can not be checked.
+func isStale(h *lockHolder) bool {
+	host, err := os.Hostname()
+	if err != nil || h.Host != host || h.Pid == os.Getpid() {
+		return false
+	}
+
+	return !processAlive(h.Pid)
+}
@@ -0,0 +1,20 @@
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package cache
+
+import "os"
+
+// File locks are not supported on this platform. Locks only apply within the
+// running instance of Glide.
+
+func tryLockFile(f *os.File, exclusive bool) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
+
+func processAlive(pid int) bool {
+	return true
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "glide-lock-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	timeout := LockTimeout
+	LockTimeout = 200 * time.Millisecond
+	defer func() { LockTimeout = timeout }()
+
+	p := filepath.Join(tmp, "test.lock")
+
+	// Shared locks can be held at the same time.
+	s1, err := lockFile(p, false, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := lockFile(p, false, "test")
+	if err != nil {
+		t.Fatalf("Expected a second shared lock but got %s", err)
+	}
+	if _, err := lockFile(p, true, "test"); err == nil || !strings.Contains(err.Error(), "Timed out") {
+		t.Errorf("Expected an exclusive lock to time out while shared locks are held but got %v", err)
+	}
+	s1.unlock()
+	s2.unlock()
+
+	l, err := lockFile(p, true, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := readHolder(p)
+	if h == nil || h.Pid != os.Getpid() {
+		t.Errorf("Expected the holder of the lock to be recorded but got %v", h)
+	}
+	if _, err := lockFile(p, false, "test"); err == nil || !strings.Contains(err.Error(), "--lock-timeout") {
+		t.Errorf("Expected a shared lock to time out while an exclusive lock is held but got %v", err)
+	}
+	l.unlock()
+	if h := readHolder(p); h != nil {
+		t.Errorf("Expected the holder to be cleared on unlock but got %v", h)
+	}
+}
+
+func TestLockFileStale(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "glide-lock-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	timeout := LockTimeout
+	LockTimeout = 5 * time.Second
+	defer func() { LockTimeout = timeout }()
+
+	// The pid of a process that has exited.
+	cmd := exec.Command("go", "version")
+	if err := cmd.Run(); err != nil {
+		t.Skip("Unable to start a process")
+	}
+	pid := cmd.Process.Pid
+
+	// A lock that lingers after its holder exited, as can happen on network
+	// file systems.
+	p := filepath.Join(tmp, "test.lock")
+	l, err := lockFile(p, true, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.unlock()
+	host, _ := os.Hostname()
+	b, _ := json.Marshal(&lockHolder{Pid: pid, Host: host, Time: time.Now().Format(time.RFC3339)})
+	if err := ioutil.WriteFile(p, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l2, err := lockFile(p, true, "test")
+	if err != nil {
+		t.Fatalf("Expected the stale lock to be removed but got %s", err)
+	}
+	l2.unlock()
+}
@@ -54,6 +54,52 @@ type Config struct {
 	// DevImports contains the test or other development imports for a project.
 	// See the Dependency type for more details on how this is recorded.
 	DevImports Dependencies `yaml:"testImport,omitempty"`
+
+	// Overrides force the version, repository, VCS type, or path of a package over
+	// what the project and its dependencies ask for.
+	Overrides Dependencies `yaml:"overrides,omitempty"`
+
+	// Concurrency limits the number of operations run at the same time. Flags
+	// take precedence over it.
+	Concurrency *Concurrency `yaml:"concurrency,omitempty"`
+}
+
+// Concurrency limits the number of operations run at the same time. Zero
+// values are not set.
+type Concurrency struct {
+
+	// Jobs is the number of workers fetching, setting versions, and exporting
+	// dependencies.
+	Jobs int `yaml:"jobs,omitempty"`
+
+	// HostJobs is the number of fetches from one host allowed at the same
+	// time.
+	HostJobs int `yaml:"hostJobs,omitempty"`
+
+	// FetchRate is the number of fetches started per second over all hosts.
+	FetchRate float64 `yaml:"fetchRate,omitempty"`
+}
+
+// Merge returns the values of c with the unset ones taken from o.
+func (c *Concurrency) Merge(o *Concurrency) *Concurrency {
+	n := &Concurrency{}
+	if c != nil {
+		*n = *c
+	}
+	if o == nil {
+		return n
+	}
+	if n.Jobs == 0 {
+		n.Jobs = o.Jobs
+	}
+	if n.HostJobs == 0 {
+		n.HostJobs = o.HostJobs
+	}
+	if n.FetchRate == 0 {
+		n.FetchRate = o.FetchRate
+	}
+
+	return n
 }
 
 // A transitive representation of a dependency for importing and exporting to yaml.
@@ -67,6 +113,8 @@ type cf struct {
 	Exclude     []string     `yaml:"excludeDirs,omitempty"`
 	Imports     Dependencies `yaml:"import"`
 	DevImports  Dependencies `yaml:"testImport,omitempty"`
+	Overrides   Dependencies `yaml:"overrides,omitempty"`
+	Concurrency *Concurrency `yaml:"concurrency,omitempty"`
 }
 
 // ConfigFromYaml returns an instance of Config from YAML
@@ -100,6 +148,8 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	c.Exclude = newConfig.Exclude
 	c.Imports = newConfig.Imports
 	c.DevImports = newConfig.DevImports
+	c.Overrides = newConfig.Overrides
+	c.Concurrency = newConfig.Concurrency
 
 	// Cleanup the Config object now that we have it.
 	err := c.DeDupe()
@@ -117,6 +167,7 @@ func (c *Config) MarshalYAML() (interface{}, error) {
 		Owners:      c.Owners,
 		Ignore:      c.Ignore,
 		Exclude:     c.Exclude,
+		Concurrency: c.Concurrency,
 	}
 	i, err := c.Imports.Clone().DeDupe()
 	if err != nil {
@@ -128,8 +179,14 @@ func (c *Config) MarshalYAML() (interface{}, error) {
 		return newConfig, err
 	}
 
+	o, err := c.Overrides.Clone().DeDupe()
+	if err != nil {
+		return newConfig, err
+	}
+
 	newConfig.Imports = i
 	newConfig.DevImports = di
+	newConfig.Overrides = o
 
 	return newConfig, nil
 }
@@ -187,6 +244,11 @@ func (c *Config) Clone() *Config {
 	n.Exclude = c.Exclude
 	n.Imports = c.Imports.Clone()
 	n.DevImports = c.DevImports.Clone()
+	n.Overrides = c.Overrides.Clone()
+	if c.Concurrency != nil {
+		cc := *c.Concurrency
+		n.Concurrency = &cc
+	}
 	return n
 }
 
@@ -215,6 +277,10 @@ func (c *Config) DeDupe() error {
 	if err != nil {
 		return err
 	}
+	c.Overrides, err = c.Overrides.DeDupe()
+	if err != nil {
+		return err
+	}
 
 	// If the name on the config object is part of the imports remove it.
 	found := -1
@@ -277,6 +343,12 @@ func (c *Config) AddImport(deps ...*Dependency) error {
 
 // Hash generates a sha256 hash for a given Config
 func (c *Config) Hash() (string, error) {
+	// The concurrency settings do not change the dependencies.
+	if c.Concurrency != nil {
+		c = c.Clone()
+		c.Concurrency = nil
+	}
+
 	yml, err := c.Marshal()
 	if err != nil {
 		return "", err
@@ -354,7 +426,7 @@ func (d Dependencies) DeDupe() (Dependencies, error) {
 			if dep.Reference != v.Reference {
 				return d, fmt.Errorf("Import %s repeated with different versions '%s' and '%s'", dep.Name, dep.Reference, v.Reference)
 			}
-			if dep.Repository != v.Repository || dep.VcsType != v.VcsType {
+			if dep.Repository != v.Repository || dep.VcsType != v.VcsType || dep.Path != v.Path || dep.Archive != v.Archive {
 				return d, fmt.Errorf("Import %s repeated with different Repository details", dep.Name)
 			}
 			if !reflect.DeepEqual(dep.Os, v.Os) || !reflect.DeepEqual(dep.Arch, v.Arch) {
@@ -377,6 +449,24 @@ type Dependency struct {
 	Subpackages []string `yaml:"subpackages,omitempty"`
 	Arch        []string `yaml:"arch,omitempty"`
 	Os          []string `yaml:"os,omitempty"`
+
+	// Path is a local directory to use for the package instead of fetching
+	// it. A relative path is relative to the directory of the glide.yaml file.
+	Path string `yaml:"path,omitempty"`
+
+	// Archive is the URL of a tarball or zip archive to download the package
+	// from instead of a repository. Checksum is the expected checksum of the
+	// archive in the form sha256:<hex>.
+	Archive  string `yaml:"archive,omitempty"`
+	Checksum string `yaml:"checksum,omitempty"`
+
+	// Shallow sets whether the Git repository of the package is cloned
+	// shallowly, overriding the --shallow flag. It is nil when not set.
+	Shallow *bool `yaml:"shallow,omitempty"`
+
+	// Hash is the hash of the exported contents. It is recorded in and read
+	// from the lock file rather than glide.yaml.
+	Hash string `yaml:"-"`
 }
 
 // A transitive representation of a dependency for importing and exploting to yaml.
@@ -389,10 +479,27 @@ type dep struct {
 	Subpackages []string `yaml:"subpackages,omitempty"`
 	Arch        []string `yaml:"arch,omitempty"`
 	Os          []string `yaml:"os,omitempty"`
+	Path        string   `yaml:"path,omitempty"`
+	Archive     string   `yaml:"archive,omitempty"`
+	Checksum    string   `yaml:"checksum,omitempty"`
+	Shallow     *bool    `yaml:"shallow,omitempty"`
 }
 
 // DependencyFromLock converts a Lock to a Dependency
 func DependencyFromLock(lock *Lock) *Dependency {
+	// The version of an archive is its checksum.
+	if lock.Archive != "" {
+		return &Dependency{
+			Name:        lock.Name,
+			Archive:     lock.Archive,
+			Checksum:    lock.Version,
+			Subpackages: lock.Subpackages,
+			Arch:        lock.Arch,
+			Os:          lock.Os,
+			Hash:        lock.Hash,
+		}
+	}
+
 	return &Dependency{
 		Name:        lock.Name,
 		Reference:   lock.Version,
@@ -401,6 +508,8 @@ func DependencyFromLock(lock *Lock) *Dependency {
 		Subpackages: lock.Subpackages,
 		Arch:        lock.Arch,
 		Os:          lock.Os,
+		Path:        lock.Path,
+		Hash:        lock.Hash,
 	}
 }
 
@@ -418,6 +527,10 @@ func (d *Dependency) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	d.Subpackages = newDep.Subpackages
 	d.Arch = newDep.Arch
 	d.Os = newDep.Os
+	d.Path = newDep.Path
+	d.Archive = newDep.Archive
+	d.Checksum = newDep.Checksum
+	d.Shallow = newDep.Shallow
 
 	if d.Reference == "" && newDep.Ref != "" {
 		d.Reference = newDep.Ref
@@ -455,17 +568,24 @@ func (d *Dependency) MarshalYAML() (interface{}, error) {
 		Subpackages: d.Subpackages,
 		Arch:        d.Arch,
 		Os:          d.Os,
+		Path:        d.Path,
+		Archive:     d.Archive,
+		Checksum:    d.Checksum,
+		Shallow:     d.Shallow,
 	}
 
 	return newDep, nil
 }
 
 // Remote returns the remote location to fetch source from. This location is
-// the central place where mirrors can alter the location.
+// the central place where mirrors can alter the location. For a dependency
+// downloaded from an archive it is the URL of the archive.
 func (d *Dependency) Remote() string {
 	var r string
 
-	if d.Repository != "" {
+	if d.Archive != "" {
+		r = d.Archive
+	} else if d.Repository != "" {
 		r = d.Repository
 	} else {
 		r = "https://" + d.Name
@@ -497,6 +617,11 @@ func (d *Dependency) Vcs() string {
 	return d.VcsType
 }
 
+// Offline makes GetRepo detect the VCS of a dependency from its checkout
+// rather than from the remote, which can require the network. It is set for
+// offline mode by the repo package.
+var Offline bool
+
 // GetRepo retrieves a Masterminds/vcs repo object configured for the root
 // of the package being retrieved.
 func (d *Dependency) GetRepo(dest string) (vcs.Repo, error) {
@@ -507,6 +632,14 @@ func (d *Dependency) GetRepo(dest string) (vcs.Repo, error) {
 
 	VcsType := d.Vcs()
 
+	// Detecting the type from the remote can require the network. In offline
+	// mode the cached checkout is used instead.
+	if len(VcsType) == 0 && Offline {
+		if t, err := vcs.DetectVcsFromFS(dest); err == nil {
+			VcsType = string(t)
+		}
+	}
+
 	// If the VCS type has a value we try that first.
 	if len(VcsType) > 0 && VcsType != "None" {
 		switch vcs.Type(VcsType) {
@@ -538,6 +671,11 @@ func (d *Dependency) Clone() *Dependency {
 		Subpackages: d.Subpackages,
 		Arch:        d.Arch,
 		Os:          d.Os,
+		Path:        d.Path,
+		Archive:     d.Archive,
+		Checksum:    d.Checksum,
+		Shallow:     d.Shallow,
+		Hash:        d.Hash,
 	}
 }
 
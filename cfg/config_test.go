@@ -176,3 +176,84 @@ func TestOwners(t *testing.T) {
 		t.Error("Unable to parse owners from yaml")
 	}
 }
+
+func TestConfigOverrides(t *testing.T) {
+	y := `
+package: fake/testing
+import:
+  - package: github.com/Masterminds/semver
+overrides:
+  - package: github.com/Masterminds/vcs
+    version: v1.8.0
+    repo: https://github.com/example/vcs
+    vcs: git
+`
+	c, err := ConfigFromYaml([]byte(y))
+	if err != nil {
+		t.Fatalf("ConfigFromYaml failed to parse yaml: %s", err)
+	}
+
+	o := c.Overrides.Get("github.com/Masterminds/vcs")
+	if o == nil {
+		t.Fatal("Unable to find the override for github.com/Masterminds/vcs")
+	}
+	if o.Reference != "v1.8.0" || o.Repository != "https://github.com/example/vcs" || o.VcsType != "git" {
+		t.Errorf("Inaccurate override found %+v", o)
+	}
+	if c.HasDependency("github.com/Masterminds/vcs") {
+		t.Error("An override should not be listed as a dependency")
+	}
+
+	c2 := c.Clone()
+	c2.Overrides[0].Reference = "v1.9.0"
+	if o.Reference != "v1.8.0" {
+		t.Error("Cloning Config overrides failed")
+	}
+
+	out, err := c.Marshal()
+	if err != nil {
+		t.Fatalf("Unable to marshal config: %s", err)
+	}
+	c3, err := ConfigFromYaml(out)
+	if err != nil {
+		t.Fatalf("Unable to parse marshaled config: %s", err)
+	}
+	if len(c3.Overrides) != 1 || c3.Overrides[0].Reference != "v1.8.0" {
+		t.Error("Overrides were not marshaled")
+	}
+}
+
+func TestConfigConcurrency(t *testing.T) {
+	c, err := ConfigFromYaml([]byte(`package: fake/testing
+concurrency:
+  jobs: 4
+  hostJobs: 2
+import:
+- package: github.com/kylelemons/go-gypsy
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Concurrency == nil || c.Concurrency.Jobs != 4 || c.Concurrency.HostJobs != 2 {
+		t.Fatalf("Unexpected concurrency %+v", c.Concurrency)
+	}
+
+	m := (&Concurrency{Jobs: 8}).Merge(c.Concurrency)
+	if m.Jobs != 8 || m.HostJobs != 2 || m.FetchRate != 0 {
+		t.Errorf("Unexpected merged concurrency %+v", m)
+	}
+
+	// The concurrency settings do not change the hash.
+	h1, err := c.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Concurrency = nil
+	h2, err := c.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h1 != h2 {
+		t.Error("Expected the concurrency settings not to change the hash")
+	}
+}
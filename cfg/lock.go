@@ -106,6 +106,23 @@ func (lf *Lockfile) Fingerprint() ([32]byte, error) {
 	return sha256.Sum256(yml), nil
 }
 
+// Local returns the names of the dependencies copied from a local directory.
+func (lf *Lockfile) Local() []string {
+	var names []string
+	for _, l := range lf.Imports {
+		if l.Path != "" {
+			names = append(names, l.Name)
+		}
+	}
+	for _, l := range lf.DevImports {
+		if l.Path != "" {
+			names = append(names, l.Name)
+		}
+	}
+
+	return names
+}
+
 // ReadLockFile loads the contents of a glide.lock file.
 func ReadLockFile(lockpath string) (*Lockfile, error) {
 	yml, err := ioutil.ReadFile(lockpath)
@@ -162,6 +179,18 @@ type Lock struct {
 	Subpackages []string `yaml:"subpackages,omitempty"`
 	Arch        []string `yaml:"arch,omitempty"`
 	Os          []string `yaml:"os,omitempty"`
+
+	// Path is the local directory the dependency was copied from. It is
+	// empty for dependencies fetched from a repository.
+	Path string `yaml:"path,omitempty"`
+
+	// Archive is the URL of the archive the dependency was downloaded from.
+	// Its Version is the checksum of the archive.
+	Archive string `yaml:"archive,omitempty"`
+
+	// Hash is a hash of the exported contents of the dependency. It is used
+	// to verify that the contents of vendor/ match what was locked.
+	Hash string `yaml:"hash,omitempty"`
 }
 
 // Clone creates a clone of a Lock.
@@ -174,6 +203,9 @@ func (l *Lock) Clone() *Lock {
 		Subpackages: l.Subpackages,
 		Arch:        l.Arch,
 		Os:          l.Os,
+		Path:        l.Path,
+		Archive:     l.Archive,
+		Hash:        l.Hash,
 	}
 }
 
@@ -187,6 +219,9 @@ func LockFromDependency(dep *Dependency) *Lock {
 		Subpackages: dep.Subpackages,
 		Arch:        dep.Arch,
 		Os:          dep.Os,
+		Path:        dep.Path,
+		Archive:     dep.Archive,
+		Hash:        dep.Hash,
 	}
 }
 
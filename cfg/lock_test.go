@@ -110,3 +110,57 @@ func TestSortSubpackages(t *testing.T) {
 		t.Errorf("Expected %q\n to contain\n%q", string(out), expectSubpkgYaml)
 	}
 }
+
+func TestLockfileLocal(t *testing.T) {
+	lf, err := LockfileFromYaml([]byte(`hash: abc
+updated: 2016-01-01T00:00:00Z
+imports:
+- name: github.com/example/local
+  version: ""
+  path: ../local
+- name: github.com/example/remote
+  version: 1234abcd
+testImports:
+- name: github.com/example/testlocal
+  version: ""
+  path: /src/testlocal
+`))
+	if err != nil {
+		t.Fatalf("Unable to parse lock file: %s", err)
+	}
+
+	local := lf.Local()
+	if len(local) != 2 || local[0] != "github.com/example/local" || local[1] != "github.com/example/testlocal" {
+		t.Errorf("Unexpected local dependencies %v", local)
+	}
+
+	d := DependencyFromLock(lf.Imports[0])
+	if d.Path != "../local" {
+		t.Errorf("Expected the path ../local but got %q", d.Path)
+	}
+	if l := LockFromDependency(d); l.Path != "../local" {
+		t.Errorf("Expected the locked path ../local but got %q", l.Path)
+	}
+}
+
+func TestLockArchive(t *testing.T) {
+	d := &Dependency{
+		Name:     "example.com/foo",
+		Archive:  "https://example.com/foo-1.0.0.tar.gz",
+		Checksum: "sha256:abcd",
+		Pin:      "sha256:abcd",
+	}
+
+	l := LockFromDependency(d)
+	if l.Archive != d.Archive || l.Version != "sha256:abcd" {
+		t.Errorf("Unexpected lock for archive %+v", l)
+	}
+
+	d2 := DependencyFromLock(l)
+	if d2.Archive != d.Archive || d2.Checksum != "sha256:abcd" || d2.Reference != "" {
+		t.Errorf("Unexpected dependency from archive lock %+v", d2)
+	}
+	if d2.Remote() != d.Archive {
+		t.Errorf("Expected the remote %s but got %s", d.Archive, d2.Remote())
+	}
+}
@@ -0,0 +1,173 @@
+// Package dep provides basic importing of dep's Gopkg.toml and Gopkg.lock files.
+//
+// This is not a complete implementation of dep.
+package dep
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/util"
+	"github.com/Masterminds/semver"
+)
+
+const (
+	manifestName = "Gopkg.toml"
+	lockName     = "Gopkg.lock"
+)
+
+// Has returns true if this dir has a Gopkg.toml or Gopkg.lock file.
+func Has(dir string) bool {
+	for _, n := range []string{manifestName, lockName} {
+		if fi, err := os.Stat(filepath.Join(dir, n)); err == nil && !fi.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses dep's Gopkg.toml and Gopkg.lock files.
+//
+// The constraints and overrides in Gopkg.toml set the version and source of a
+// dependency. Projects in Gopkg.lock without either use the locked version or
+// revision. The packages listed as required are added as subpackages.
+func Parse(dir string) ([]*cfg.Dependency, error) {
+	buf := []*cfg.Dependency{}
+	if !Has(dir) {
+		return buf, nil
+	}
+
+	msg.Info("Found dep configuration in %s", gpath.StripBasepath(dir))
+	msg.Info("--> Parsing dep metadata...")
+
+	manifest, err := load(filepath.Join(dir, manifestName))
+	if err != nil {
+		return buf, err
+	}
+	lock, err := load(filepath.Join(dir, lockName))
+	if err != nil {
+		return buf, err
+	}
+
+	get := func(name string) *cfg.Dependency {
+		for _, d := range buf {
+			if d.Name == name {
+				return d
+			}
+		}
+		d := &cfg.Dependency{Name: name}
+		buf = append(buf, d)
+		return d
+	}
+
+	// Overrides come after the constraints so they take precedence.
+	for _, c := range append(manifest.tables("constraint"), manifest.tables("override")...) {
+		name, _ := util.NormalizeName(c.str("name"))
+		if name == "" {
+			continue
+		}
+		d := get(name)
+		if ref := reference(c); ref != "" {
+			d.Reference = ref
+		}
+		if src := c.str("source"); src != "" {
+			d.Repository = source(src)
+		}
+	}
+
+	for _, p := range lock.tables("projects") {
+		name, _ := util.NormalizeName(p.str("name"))
+		if name == "" {
+			continue
+		}
+		d := get(name)
+		if d.Reference == "" {
+			d.Reference = p.str("version")
+			if d.Reference == "" {
+				d.Reference = p.str("revision")
+			}
+		}
+		if d.Repository == "" && p.str("source") != "" {
+			d.Repository = source(p.str("source"))
+		}
+		for _, sp := range p.strs("packages") {
+			if sp != "." && sp != "" && !d.HasSubpackage(sp) {
+				d.Subpackages = append(d.Subpackages, sp)
+			}
+		}
+	}
+
+	for _, r := range manifest.strs("required") {
+		name, sub := util.NormalizeName(r)
+		d := get(name)
+		if sub != "" && !d.HasSubpackage(sub) {
+			d.Subpackages = append(d.Subpackages, sub)
+		}
+	}
+
+	return buf, nil
+}
+
+// Ignored returns the packages ignored in a Gopkg.toml file. Wildcards, such
+// as github.com/foo/bar/*, are turned into the package prefix since Glide
+// ignores subpackages of an ignored package as well.
+func Ignored(dir string) ([]string, error) {
+	manifest, err := load(filepath.Join(dir, manifestName))
+	if err != nil {
+		return []string{}, err
+	}
+
+	ign := []string{}
+	for _, i := range manifest.strs("ignored") {
+		ign = append(ign, strings.TrimSuffix(strings.TrimSuffix(i, "*"), "/"))
+	}
+
+	return ign, nil
+}
+
+// load parses a TOML file. A missing file is an empty table.
+func load(path string) (table, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return table{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseToml(file)
+}
+
+// reference converts the version, branch or revision of a dep constraint to
+// a Glide reference.
+func reference(c table) string {
+	if v := strings.TrimSpace(c.str("version")); v != "" {
+		// dep treats a bare version as a caret range while Glide treats it as
+		// an exact version. A leading = is how dep asks for an exact version.
+		if strings.HasPrefix(v, "=") {
+			return strings.TrimSpace(v[1:])
+		}
+		if _, err := semver.NewVersion(v); err == nil {
+			return "^" + v
+		}
+		return v
+	}
+	if b := c.str("branch"); b != "" {
+		return b
+	}
+
+	return c.str("revision")
+}
+
+// source converts a dep source, which may be an import path, to a repository
+// location.
+func source(src string) string {
+	if strings.Contains(src, "://") || strings.Contains(src, "@") {
+		return src
+	}
+	return "https://" + src
+}
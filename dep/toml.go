@@ -0,0 +1,303 @@
+package dep
+
+// This is a small parser for the subset of TOML used by dep's Gopkg.toml and
+// Gopkg.lock files. It handles tables, arrays of tables, strings, booleans,
+// numbers and arrays of those. Anything else is reported as an error.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// table holds the keys of a TOML table. The values are string, bool, []string,
+// table or []table.
+type table map[string]interface{}
+
+// str returns the string value for a key or an empty string.
+func (t table) str(key string) string {
+	s, _ := t[key].(string)
+	return s
+}
+
+// strs returns the array of strings for a key.
+func (t table) strs(key string) []string {
+	s, _ := t[key].([]string)
+	return s
+}
+
+// tables returns the array of tables for a key.
+func (t table) tables(key string) []table {
+	s, _ := t[key].([]table)
+	return s
+}
+
+func parseToml(r io.Reader) (table, error) {
+	root := table{}
+	cur := root
+
+	s := bufio.NewScanner(r)
+	n := 0
+	for s.Scan() {
+		n++
+		line := strings.TrimSpace(stripComment(s.Text()))
+		if line == "" {
+			continue
+		}
+
+		var err error
+		switch {
+		case strings.HasPrefix(line, "[["):
+			if !strings.HasSuffix(line, "]]") {
+				return nil, fmt.Errorf("line %d: invalid array of tables %q", n, line)
+			}
+			cur, err = arrayTable(root, strings.TrimSpace(line[2:len(line)-2]))
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid table %q", n, line)
+			}
+			cur, err = subTable(root, strings.TrimSpace(line[1:len(line)-1]))
+		default:
+			i := strings.Index(line, "=")
+			if i == -1 {
+				return nil, fmt.Errorf("line %d: expected a key and value in %q", n, line)
+			}
+			key := unquoteKey(strings.TrimSpace(line[:i]))
+			val := strings.TrimSpace(line[i+1:])
+			if key == "" {
+				return nil, fmt.Errorf("line %d: missing key in %q", n, line)
+			}
+
+			// Arrays may span multiple lines.
+			for strings.HasPrefix(val, "[") && !arrayClosed(val) && s.Scan() {
+				n++
+				val += " " + strings.TrimSpace(stripComment(s.Text()))
+			}
+
+			var v interface{}
+			v, err = parseValue(val)
+			if err == nil {
+				cur[key] = v
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+	}
+
+	return root, s.Err()
+}
+
+// subTable returns the table for a [a.b] header, creating it when needed.
+func subTable(root table, name string) (table, error) {
+	t := root
+	for _, k := range splitKey(name) {
+		k = unquoteKey(strings.TrimSpace(k))
+		if k == "" {
+			return nil, fmt.Errorf("invalid table name %q", name)
+		}
+		switch v := t[k].(type) {
+		case nil:
+			nt := table{}
+			t[k] = nt
+			t = nt
+		case table:
+			t = v
+		case []table:
+			t = v[len(v)-1]
+		default:
+			return nil, fmt.Errorf("key %s is not a table", k)
+		}
+	}
+
+	return t, nil
+}
+
+// arrayTable appends a new table for a [[a.b]] header and returns it.
+func arrayTable(root table, name string) (table, error) {
+	parent := root
+	if keys := splitKey(name); len(keys) > 1 {
+		var err error
+		if parent, err = subTable(root, strings.Join(keys[:len(keys)-1], ".")); err != nil {
+			return nil, err
+		}
+		name = keys[len(keys)-1]
+	}
+	name = unquoteKey(strings.TrimSpace(name))
+	if name == "" {
+		return nil, fmt.Errorf("invalid array of tables name")
+	}
+
+	nt := table{}
+	switch v := parent[name].(type) {
+	case nil:
+		parent[name] = []table{nt}
+	case []table:
+		parent[name] = append(v, nt)
+	default:
+		return nil, fmt.Errorf("key %s is not an array of tables", name)
+	}
+
+	return nt, nil
+}
+
+func parseValue(val string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(val, "\"") || strings.HasPrefix(val, "'"):
+		s, rest, err := parseString(val)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(rest) != "" {
+			return nil, fmt.Errorf("unexpected %q after string", rest)
+		}
+		return s, nil
+	case strings.HasPrefix(val, "["):
+		return parseArray(val)
+	case val == "true" || val == "false":
+		return val == "true", nil
+	case strings.HasPrefix(val, "{"):
+		return nil, fmt.Errorf("inline tables are not supported")
+	case val != "" && strings.IndexAny(val[:1], "+-0123456789") == 0:
+		return val, nil
+	}
+
+	return nil, fmt.Errorf("unsupported value %q", val)
+}
+
+func parseArray(val string) ([]string, error) {
+	arr := []string{}
+	rest := strings.TrimSpace(val[1:])
+	for {
+		if strings.HasPrefix(rest, "]") {
+			if strings.TrimSpace(rest[1:]) != "" {
+				return nil, fmt.Errorf("unexpected %q after array", rest[1:])
+			}
+			return arr, nil
+		}
+		if rest == "" {
+			return nil, fmt.Errorf("unterminated array %q", val)
+		}
+
+		var s string
+		var err error
+		if strings.HasPrefix(rest, "\"") || strings.HasPrefix(rest, "'") {
+			s, rest, err = parseString(rest)
+			if err != nil {
+				return nil, err
+			}
+		} else if strings.HasPrefix(rest, "[") || strings.HasPrefix(rest, "{") {
+			return nil, fmt.Errorf("nested arrays and inline tables are not supported in %q", val)
+		} else {
+			i := strings.IndexAny(rest, ",]")
+			if i == -1 {
+				return nil, fmt.Errorf("unterminated array %q", val)
+			}
+			s, rest = strings.TrimSpace(rest[:i]), rest[i:]
+		}
+		arr = append(arr, s)
+
+		rest = strings.TrimSpace(rest)
+		if strings.HasPrefix(rest, ",") {
+			rest = strings.TrimSpace(rest[1:])
+		} else if !strings.HasPrefix(rest, "]") {
+			return nil, fmt.Errorf("expected , or ] in array %q", val)
+		}
+	}
+}
+
+// parseString parses a basic or literal string at the start of val and
+// returns it along with the remaining text.
+func parseString(val string) (string, string, error) {
+	q := val[0]
+	for i := 1; i < len(val); i++ {
+		switch val[i] {
+		case '\\':
+			if q == '"' {
+				i++
+			}
+		case q:
+			if q == '\'' {
+				return val[1:i], val[i+1:], nil
+			}
+			s, err := strconv.Unquote(val[:i+1])
+			return s, val[i+1:], err
+		}
+	}
+
+	return "", "", fmt.Errorf("unterminated string %q", val)
+}
+
+// stripComment removes a trailing # comment that is not within a string.
+func stripComment(line string) string {
+	var q byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case q != 0 && c == '\\' && q == '"':
+			i++
+		case q != 0 && c == q:
+			q = 0
+		case q == 0 && (c == '"' || c == '\''):
+			q = c
+		case q == 0 && c == '#':
+			return line[:i]
+		}
+	}
+
+	return line
+}
+
+// arrayClosed reports if the brackets of an array value are balanced outside
+// of strings.
+func arrayClosed(val string) bool {
+	var q byte
+	depth := 0
+	for i := 0; i < len(val); i++ {
+		c := val[i]
+		switch {
+		case q != 0 && c == '\\' && q == '"':
+			i++
+		case q != 0 && c == q:
+			q = 0
+		case q == 0 && (c == '"' || c == '\''):
+			q = c
+		case q == 0 && c == '[':
+			depth++
+		case q == 0 && c == ']':
+			depth--
+		}
+	}
+
+	return depth <= 0
+}
+
+// splitKey splits a dotted key on the dots outside of quotes.
+func splitKey(name string) []string {
+	var keys []string
+	var q byte
+	start := 0
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case q != 0 && c == q:
+			q = 0
+		case q == 0 && (c == '"' || c == '\''):
+			q = c
+		case q == 0 && c == '.':
+			keys = append(keys, name[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(keys, name[start:])
+}
+
+func unquoteKey(k string) string {
+	if len(k) >= 2 && (k[0] == '"' || k[0] == '\'') && k[len(k)-1] == k[0] {
+		return k[1 : len(k)-1]
+	}
+	return k
+}
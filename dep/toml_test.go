@@ -0,0 +1,185 @@
+package dep
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseToml(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		want table
+	}{
+		{
+			name: "strings",
+			toml: `a = "basic"
+b = 'literal \n'
+c = "escaped \"quote\" and \\ and \t"
+"quoted key" = "x"
+'literal key' = "y"
+`,
+			want: table{
+				"a":           "basic",
+				"b":           `literal \n`,
+				"c":           "escaped \"quote\" and \\ and \t",
+				"quoted key":  "x",
+				"literal key": "y",
+			},
+		},
+		{
+			name: "comments",
+			toml: `# a comment
+a = "value" # trailing comment
+b = "not # a comment"
+c = 'also # not' # but this is
+   # indented comment
+`,
+			want: table{"a": "value", "b": "not # a comment", "c": "also # not"},
+		},
+		{
+			name: "scalars",
+			toml: "t = true\nf = false\ni = 1\nn = -2\nx = 3.5\n",
+			want: table{"t": true, "f": false, "i": "1", "n": "-2", "x": "3.5"},
+		},
+		{
+			name: "arrays",
+			toml: `empty = []
+one = ["a"]
+multi = [
+  "b", # first
+  'c',
+]
+trailing = [ "d", "e", ]
+numbers = [1, 2]
+`,
+			want: table{
+				"empty":    []string{},
+				"one":      []string{"a"},
+				"multi":    []string{"b", "c"},
+				"trailing": []string{"d", "e"},
+				"numbers":  []string{"1", "2"},
+			},
+		},
+		{
+			name: "tables",
+			toml: `[a]
+x = "1"
+[a.b]
+y = "2"
+["c.d"]
+z = "3"
+`,
+			want: table{
+				"a":   table{"x": "1", "b": table{"y": "2"}},
+				"c.d": table{"z": "3"},
+			},
+		},
+		{
+			name: "arrays of tables",
+			toml: `[[p]]
+name = "one"
+[[p]]
+name = "two"
+[p.meta]
+k = "v"
+[[q.r]]
+name = "three"
+`,
+			want: table{
+				"p": []table{{"name": "one"}, {"name": "two", "meta": table{"k": "v"}}},
+				"q": table{"r": []table{{"name": "three"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := parseToml(strings.NewReader(tt.toml))
+		if err != nil {
+			t.Errorf("%s: Unexpected error: %s", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Expected %#v but got %#v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestParseTomlErrors(t *testing.T) {
+	tests := []string{
+		`a = "unterminated`,
+		`a = 'unterminated`,
+		`a = "x" junk`,
+		`a = ["x"`,
+		`a = ["x" "y"]`,
+		`a = ["x"] junk`,
+		`a = [["nested"]]`,
+		`a = { b = "inline" }`,
+		`a = [{ b = "inline" }]`,
+		`a = bare`,
+		`a =`,
+		`= "no key"`,
+		`just a key`,
+		`[unterminated`,
+		`[[unterminated]`,
+		`[]`,
+		`[[]]`,
+		`[a..b]`,
+		"a = \"x\"\n[a]",
+		"[[a]]\n[a.b]\nx = \"1\"\n[[a.b]]",
+		"a = \"x\\",
+	}
+
+	for _, tt := range tests {
+		if _, err := parseToml(strings.NewReader(tt)); err == nil {
+			t.Errorf("Expected an error parsing %q", tt)
+		}
+	}
+}
+
+func TestParseFixtures(t *testing.T) {
+	deps, err := Parse("../testdata/dep")
+	if err != nil {
+		t.Fatalf("Unexpected error parsing: %s", err)
+	}
+
+	type want struct {
+		ref, repo string
+		subs      []string
+	}
+	wants := map[string]want{
+		"github.com/Masterminds/semver":        {ref: "^1.4.0"},
+		"github.com/Masterminds/vcs":           {ref: "v1.11.1"},
+		"github.com/pkg/errors":                {ref: "master"},
+		"github.com/example/forked":            {ref: "0c5e0ac5f2d4fa5c7b3eb4a1b6b5b5d4a0d2c3f1", repo: "https://github.com/someone/forked"},
+		"gopkg.in/yaml.v2":                     {ref: ">=2.1.0, <2.3.0"},
+		"github.com/golang/protobuf":           {ref: "v1.1.0", subs: []string{"proto", "protoc-gen-go", "protoc-gen-go/descriptor"}},
+		"github.com/example/locked":            {ref: "1234567890abcdef1234567890abcdef12345678", repo: "https://git.example.com/locked.git", subs: []string{"sub"}},
+		"github.com/kubernetes/code-generator": {subs: []string{"cmd/client-gen"}},
+	}
+	if len(deps) != len(wants) {
+		t.Errorf("Expected %d dependencies but got %d", len(wants), len(deps))
+	}
+	for _, d := range deps {
+		w, ok := wants[d.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency %s", d.Name)
+			continue
+		}
+		if d.Reference != w.ref || d.Repository != w.repo {
+			t.Errorf("Expected %s at %q from %q but got %q from %q", d.Name, w.ref, w.repo, d.Reference, d.Repository)
+		}
+		if len(d.Subpackages) != len(w.subs) || (len(w.subs) > 0 && !reflect.DeepEqual(d.Subpackages, w.subs)) {
+			t.Errorf("Expected the subpackages %v of %s but got %v", w.subs, d.Name, d.Subpackages)
+		}
+	}
+
+	ign, err := Ignored("../testdata/dep")
+	if err != nil {
+		t.Fatalf("Unexpected error reading ignored packages: %s", err)
+	}
+	if !reflect.DeepEqual(ign, []string{"github.com/example/ignored", "github.com/example/skip"}) {
+		t.Errorf("Unexpected ignored packages %v", ign)
+	}
+}
@@ -0,0 +1,138 @@
+package dependency
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ImportChain is a path through the import graph. The first package is one
+// of the local packages and each package imports the one following it.
+type ImportChain struct {
+	Packages []string
+
+	// Test is true when the chain includes an import only used by tests.
+	Test bool
+}
+
+// maxChainSteps limits the packages visited while listing import chains in
+// case most of the paths through the graph end in import cycles.
+const maxChainSteps = 100000
+
+// ImportChains returns the import chains, found while resolving, from the
+// local packages to pkg or one of its subpackages. The number of chains grows
+// exponentially with packages importing the same ones so at most max are
+// returned, shortest first. more is true when there are others.
+//
+// The chains are only as complete as the resolution performed beforehand. For
+// example, after ResolveLocal(true) they cover the whole tree.
+func (r *Resolver) ImportChains(pkg string, max int) (chains []ImportChain, more bool) {
+	var targets []string
+	for name := range r.importers {
+		if name == pkg || strings.HasPrefix(name, pkg+"/") {
+			targets = append(targets, name)
+		}
+	}
+	sort.Strings(targets)
+
+	w := &chainWalk{r: r, max: max}
+	for _, t := range targets {
+		if !w.walk(t, []string{t}, false, map[string]bool{t: true}) {
+			break
+		}
+	}
+	sort.SliceStable(w.chains, func(i, j int) bool {
+		return len(w.chains[i].Packages) < len(w.chains[j].Packages)
+	})
+
+	return w.chains, w.more
+}
+
+type chainWalk struct {
+	r      *Resolver
+	max    int
+	steps  int
+	chains []ImportChain
+	more   bool
+}
+
+// walk walks up the recorded importers of pkg. The chain passed in is in
+// reverse order, starting with the target package. It returns false once the
+// limits are reached.
+func (w *chainWalk) walk(pkg string, chain []string, test bool, seen map[string]bool) bool {
+	w.steps++
+	if w.steps > maxChainSteps {
+		w.more = true
+		return false
+	}
+
+	imps := w.r.importers[pkg]
+	if len(imps) == 0 {
+		if len(w.chains) >= w.max {
+			w.more = true
+			return false
+		}
+		c := make([]string, len(chain))
+		for i, p := range chain {
+			c[len(chain)-1-i] = p
+		}
+		w.chains = append(w.chains, ImportChain{Packages: c, Test: test})
+		return true
+	}
+
+	parents := make([]string, 0, len(imps))
+	for p := range imps {
+		parents = append(parents, p)
+	}
+	sort.Strings(parents)
+
+	for _, p := range parents {
+		// Import cycles are not valid Go but can show up across versions of
+		// packages. Skip them rather than loop forever.
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		ok := w.walk(p, append(chain, p), test || imps[p], seen)
+		delete(seen, p)
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// addImporter records that parent imports pkg. An import that is not test
+// only takes precedence over a test one.
+func (r *Resolver) addImporter(pkg, parent string, test bool) {
+	if pkg == parent {
+		return
+	}
+	m, ok := r.importers[pkg]
+	if !ok {
+		m = map[string]bool{}
+		r.importers[pkg] = m
+	}
+	if t, ok := m[parent]; !ok || t {
+		m[parent] = test
+	}
+}
+
+// localName returns the import path for a directory, relative to the base
+// directory, of the local project.
+func (r *Resolver) localName(pt string) string {
+	root := pt == r.basedir || pt == "" || pt == "."
+	if r.Config.Name == "" {
+		if root {
+			return "."
+		}
+		return filepath.ToSlash(pt)
+	}
+
+	if root {
+		return r.Config.Name
+	}
+
+	return r.Config.Name + "/" + filepath.ToSlash(pt)
+}
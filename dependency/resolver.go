@@ -56,7 +56,7 @@ type MissingPackageHandler interface {
 
 	// PkgPath is called to find the location locally to scan. This gives the
 	// handler to do things such as use a cached location.
-	PkgPath(pkg string) string
+	PkgPath(pkg string) (string, error)
 }
 
 // DefaultMissingPackageHandler is the default handler for missing packages.
@@ -92,11 +92,11 @@ func (d *DefaultMissingPackageHandler) InVendor(pkg string, addTest bool) error
 }
 
 // PkgPath returns the path to the package
-func (d *DefaultMissingPackageHandler) PkgPath(pkg string) string {
+func (d *DefaultMissingPackageHandler) PkgPath(pkg string) (string, error) {
 	if d.Prefix != "" {
-		return filepath.Join(d.Prefix, pkg)
+		return filepath.Join(d.Prefix, pkg), nil
 	}
-	return pkg
+	return pkg, nil
 }
 
 // VersionHandler sets the version for a package when found while scanning.
@@ -169,6 +169,10 @@ type Resolver struct {
 	// findCache caches hits from Find. This reduces the number of filesystem
 	// touches that have to be done for dependency resolution.
 	findCache map[string]*PkgInfo
+
+	// importers records, for each package found while resolving, the
+	// packages importing it. The value notes if the import is test only.
+	importers map[string]map[string]bool
 }
 
 // NewResolver returns a new Resolver initialized with the DefaultMissingPackageHandler.
@@ -208,6 +212,7 @@ func NewResolver(basedir string) (*Resolver, error) {
 		alreadyQ:       map[string]bool{},
 		hadError:       map[string]bool{},
 		findCache:      map[string]*PkgInfo{},
+		importers:      map[string]map[string]bool{},
 
 		// The config instance here should really be replaced with a real one.
 		Config: &cfg.Config{},
@@ -285,6 +290,7 @@ func (r *Resolver) ResolveLocal(deep bool) ([]string, []string, error) {
 
 		// Scan for dependencies, and anything that's not part of the local
 		// package gets added to the scan list.
+		local := r.localName(pt)
 		var imps []string
 		var testImps []string
 		p, err := r.BuildContext.ImportDir(path, 0)
@@ -313,6 +319,7 @@ func (r *Resolver) ResolveLocal(deep bool) ([]string, []string, error) {
 			if r.Config.HasIgnore(imp) {
 				continue
 			}
+			r.addImporter(imp, local, false)
 			if alreadySeen[imp] {
 				continue
 			}
@@ -338,6 +345,7 @@ func (r *Resolver) ResolveLocal(deep bool) ([]string, []string, error) {
 
 		if r.ResolveTest {
 			for _, imp := range testImps {
+				r.addImporter(imp, local, true)
 				if talreadySeen[imp] {
 					continue
 				}
@@ -489,10 +497,16 @@ func (r *Resolver) resolveImports(queue *list.List, testDeps, addTest bool) ([]s
 			continue
 		}
 		r.VersionHandler.Process(dep)
+		pkgPath, err := r.Handler.PkgPath(dep)
+		if err != nil {
+			r.hadError[dep] = true
+			msg.Err("Error looking for %s: %s", dep, err)
+			continue
+		}
 		// Here, we want to import the package and see what imports it has.
-		msg.Debug("Trying to open %s (%s)", dep, r.Handler.PkgPath(dep))
+		msg.Debug("Trying to open %s (%s)", dep, pkgPath)
 		var imps []string
-		pkg, err := r.BuildContext.ImportDir(r.Handler.PkgPath(dep), 0)
+		pkg, err := r.BuildContext.ImportDir(pkgPath, 0)
 		if err != nil && strings.HasPrefix(err.Error(), "found packages ") {
 			// If we got here it's because a package and multiple packages
 			// declared. This is often because of an example with a package
@@ -500,9 +514,9 @@ func (r *Resolver) resolveImports(queue *list.List, testDeps, addTest bool) ([]s
 			// try to brute force the packages with a slower scan.
 			msg.Debug("Using Iterative Scanning for %s", dep)
 			if testDeps {
-				_, imps, err = IterativeScan(r.Handler.PkgPath(dep))
+				_, imps, err = IterativeScan(pkgPath)
 			} else {
-				imps, _, err = IterativeScan(r.Handler.PkgPath(dep))
+				imps, _, err = IterativeScan(pkgPath)
 			}
 
 			if err != nil {
@@ -511,11 +525,11 @@ func (r *Resolver) resolveImports(queue *list.List, testDeps, addTest bool) ([]s
 			}
 		} else if err != nil {
 			errStr := err.Error()
-			msg.Debug("ImportDir error on %s: %s", r.Handler.PkgPath(dep), err)
+			msg.Debug("ImportDir error on %s: %s", pkgPath, err)
 			if strings.HasPrefix(errStr, "no buildable Go source") {
 				msg.Debug("No subpackages declared. Skipping %s.", dep)
 				continue
-			} else if osDirNotFound(err, r.Handler.PkgPath(dep)) && !foundErr && !foundQ {
+			} else if osDirNotFound(err, pkgPath) && !foundErr && !foundQ {
 				// If the location doesn't exist, there hasn't already been an
 				// error, it's not already been in the Q then try to fetch it.
 				// When there's an error or it's already in the Q (it should be
@@ -574,6 +588,7 @@ func (r *Resolver) resolveImports(queue *list.List, testDeps, addTest bool) ([]s
 			pi := r.FindPkg(imp)
 			if pi.Loc != LocCgo && pi.Loc != LocGoroot && pi.Loc != LocAppengine {
 				msg.Debug("Package %s imports %s", dep, imp)
+				r.addImporter(imp, dep, addTest)
 			}
 			switch pi.Loc {
 			case LocVendor:
@@ -681,7 +696,6 @@ func (r *Resolver) resolveList(queue *list.List, testDeps, addTest bool) ([]stri
 
 	var failedDep string
 	var failedDepPath string
-	var pkgPath string
 	for e := queue.Front(); e != nil; e = e.Next() {
 		dep := e.Value.(string)
 		t := strings.TrimPrefix(dep, r.VendorDir+string(os.PathSeparator))
@@ -693,10 +707,14 @@ func (r *Resolver) resolveList(queue *list.List, testDeps, addTest bool) ([]stri
 		//msg.Warn("#### %s ####", dep)
 		//msg.Info("Seen Count: %d", len(r.seen))
 		// Catch the outtermost dependency.
-		pkgPath = r.Handler.PkgPath(t)
 		failedDep = t
+		pkgPath, err := r.Handler.PkgPath(t)
+		if err != nil {
+			msg.Err("Dependency %s failed to resolve: %s.", t, err)
+			return []string{}, err
+		}
 		failedDepPath = pkgPath
-		err := filepath.Walk(pkgPath, func(path string, fi os.FileInfo, err error) error {
+		err = filepath.Walk(pkgPath, func(path string, fi os.FileInfo, err error) error {
 			if err != nil && err != filepath.SkipDir {
 				return err
 			}
@@ -714,7 +732,11 @@ func (r *Resolver) resolveList(queue *list.List, testDeps, addTest bool) ([]stri
 			// Anything that comes through here has already been through
 			// the queue.
 			r.alreadyQ[path] = true
-			e := r.queueUnseen(path, queue, testDeps, addTest)
+			parent := t
+			if rel, err := filepath.Rel(pkgPath, path); err == nil && rel != "." {
+				parent = t + "/" + filepath.ToSlash(rel)
+			}
+			e := r.queueUnseen(path, parent, queue, testDeps, addTest)
 			if e != nil {
 				failedDepPath = path
 				//msg.Err("Failed to fetch dependency %s: %s", path, err)
@@ -768,8 +790,8 @@ func (r *Resolver) resolveList(queue *list.List, testDeps, addTest bool) ([]stri
 }
 
 // queueUnseenImports scans a package's imports and adds any new ones to the
-// processing queue.
-func (r *Resolver) queueUnseen(pkg string, queue *list.List, testDeps, addTest bool) error {
+// processing queue. The parent is the import path of pkg.
+func (r *Resolver) queueUnseen(pkg, parent string, queue *list.List, testDeps, addTest bool) error {
 	// A pkg is marked "seen" as soon as we have inspected it the first time.
 	// Seen means that we have added all of its imports to the list.
 
@@ -789,6 +811,7 @@ func (r *Resolver) queueUnseen(pkg string, queue *list.List, testDeps, addTest b
 	}
 
 	for _, d := range deps {
+		r.addImporter(r.Stripv(d), parent, addTest)
 		if _, ok := r.alreadyQ[d]; !ok {
 			r.alreadyQ[d] = true
 			queue.PushBack(d)
@@ -824,10 +847,14 @@ func (r *Resolver) imports(pkg string, testDeps, addTest bool) ([]string, error)
 		// declared. This is often because of an example with a package
 		// or main but +build ignore as a build tag. In that case we
 		// try to brute force the packages with a slower scan.
+		pkgPath, perr := r.Handler.PkgPath(pkg)
+		if perr != nil {
+			return []string{}, perr
+		}
 		if testDeps {
-			_, imps, err = IterativeScan(r.Handler.PkgPath(pkg))
+			_, imps, err = IterativeScan(pkgPath)
 		} else {
-			imps, _, err = IterativeScan(r.Handler.PkgPath(pkg))
+			imps, _, err = IterativeScan(pkgPath)
 		}
 
 		if err != nil {
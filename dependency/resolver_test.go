@@ -1,6 +1,7 @@
 package dependency
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -106,3 +107,64 @@ func TestResolveAll(t *testing.T) {
 		t.Errorf("Expected at least %d deps, got %d", len(deps), len(l))
 	}
 }
+
+func TestImportChainsLimit(t *testing.T) {
+	r := &Resolver{importers: map[string]map[string]bool{}}
+
+	// A chain of diamonds has 2^n paths through it.
+	prev := "local"
+	for i := 0; i < 40; i++ {
+		a, b, next := fmt.Sprintf("a%d", i), fmt.Sprintf("b%d", i), fmt.Sprintf("p%d", i)
+		r.addImporter(a, prev, false)
+		r.addImporter(b, prev, false)
+		r.addImporter(next, a, false)
+		r.addImporter(next, b, false)
+		prev = next
+	}
+
+	chains, more := r.ImportChains(prev, 10)
+	if len(chains) != 10 || !more {
+		t.Errorf("Expected 10 chains and more but got %d (%t)", len(chains), more)
+	}
+	for _, c := range chains {
+		if c.Packages[0] != "local" || c.Packages[len(c.Packages)-1] != prev {
+			t.Errorf("Unexpected chain %v", c.Packages)
+		}
+	}
+
+	chains, more = r.ImportChains("b0", 10)
+	if len(chains) != 1 || more {
+		t.Errorf("Expected a single chain to b0 but got %d (%t)", len(chains), more)
+	}
+}
+
+func TestImportChains(t *testing.T) {
+	r, err := NewResolver("../")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Config = &cfg.Config{Name: "github.com/Masterminds/glide"}
+	h := &DefaultMissingPackageHandler{Missing: []string{}, Gopath: []string{}, Prefix: "../vendor"}
+	r.Handler = h
+
+	if _, _, err = r.ResolveLocal(true); err != nil {
+		t.Fatalf("Failed to resolve: %s", err)
+	}
+
+	chains, _ := r.ImportChains("github.com/Masterminds/semver", 100)
+	if len(chains) == 0 {
+		t.Fatal("Expected import chains to github.com/Masterminds/semver")
+	}
+	for _, c := range chains {
+		if c.Packages[0] != "github.com/Masterminds/glide" && !strings.HasPrefix(c.Packages[0], "github.com/Masterminds/glide/") {
+			t.Errorf("Expected chain to start with a local package: %v", c.Packages)
+		}
+		if !strings.HasPrefix(c.Packages[len(c.Packages)-1], "github.com/Masterminds/semver") {
+			t.Errorf("Expected chain to end with semver: %v", c.Packages)
+		}
+	}
+
+	if chains, _ := r.ImportChains("github.com/example/none", 100); len(chains) != 0 {
+		t.Errorf("Expected no chains but got %v", chains)
+	}
+}
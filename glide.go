@@ -32,6 +32,7 @@ import (
 
 	"fmt"
 	"os"
+	"time"
 )
 
 var version = "0.13.2-dev"
@@ -91,6 +92,55 @@ func main() {
 			Name:  "no-color",
 			Usage: "Turn off colored output for log messages",
 		},
+		cli.StringFlag{
+			Name:   "output, log-format",
+			Value:  "text",
+			Usage:  "Format of log messages. One of: text|json. json writes one JSON object per line",
+			EnvVar: "GLIDE_OUTPUT,GLIDE_LOG_FORMAT",
+		},
+		cli.BoolFlag{
+			Name:   "offline",
+			Usage:  "Only use repositories already in the cache. Fail instead of accessing the network",
+			EnvVar: "GLIDE_OFFLINE",
+		},
+		cli.BoolFlag{
+			Name:   "shallow",
+			Usage:  "Clone Git repositories into the cache without their history. Tags and commits are fetched when needed",
+			EnvVar: "GLIDE_SHALLOW",
+		},
+		cli.IntFlag{
+			Name:   "jobs, j",
+			Usage:  "The number of dependencies to fetch, set versions for, and export at the same time. Defaults to 20",
+			EnvVar: "GLIDE_JOBS",
+		},
+		cli.IntFlag{
+			Name:   "host-jobs",
+			Usage:  "The number of fetches allowed from one host at the same time. Defaults to no limit",
+			EnvVar: "GLIDE_HOST_JOBS",
+		},
+		cli.Float64Flag{
+			Name:   "fetch-rate",
+			Usage:  "The number of fetches started per second over all hosts. Defaults to no limit",
+			EnvVar: "GLIDE_FETCH_RATE",
+		},
+		cli.IntFlag{
+			Name:   "retries",
+			Value:  2,
+			Usage:  "The number of times to retry a fetch, update, or listing of versions that failed with a transient error",
+			EnvVar: "GLIDE_RETRIES",
+		},
+		cli.DurationFlag{
+			Name:   "retry-backoff",
+			Value:  time.Second,
+			Usage:  "The wait before the first retry. It doubles for each retry after that",
+			EnvVar: "GLIDE_RETRY_BACKOFF",
+		},
+		cli.DurationFlag{
+			Name:   "lock-timeout",
+			Value:  10 * time.Minute,
+			Usage:  "How long to wait on cache locks held by other instances of Glide. 0 waits forever",
+			EnvVar: "GLIDE_LOCK_TIMEOUT",
+		},
 	}
 	app.CommandNotFound = func(c *cli.Context, command string) {
 		// TODO: Set some useful env vars.
@@ -102,14 +152,17 @@ func main() {
 
 	// Detect errors from the Before and After calls and exit on them.
 	if err := app.Run(os.Args); err != nil {
-		msg.Err(err.Error())
+		msg.Err("%s", err)
 		os.Exit(1)
 	}
 
 	// If there was an Error message exit non-zero.
 	if msg.HasErrored() {
-		m := msg.Color(msg.Red, "An Error has occurred")
-		msg.Msg(m)
+		// The errors have been reported as events in the JSON output.
+		if msg.Default.Formatter == nil {
+			m := msg.Color(msg.Red, "An Error has occurred")
+			msg.Msg(m)
+		}
 		os.Exit(2)
 	}
 }
@@ -374,6 +427,63 @@ func commands() []cli.Command {
 						return nil
 					},
 				},
+				{
+					Name:  "dep",
+					Usage: "Import dep's Gopkg.toml and Gopkg.lock files and display the would-be yaml file",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "file, f",
+							Usage: "Save all of the discovered dependencies to a Glide YAML file.",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						action.ImportDep(c.String("file"))
+						return nil
+					},
+				},
+				{
+					Name:  "govendor",
+					Usage: "Import govendor's vendor.json file and display the would-be yaml file",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "file, f",
+							Usage: "Save all of the discovered dependencies to a Glide YAML file.",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						action.ImportGovendor(c.String("file"))
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "export",
+			Usage: "Export the configuration to other dependency management systems.",
+			Subcommands: []cli.Command{
+				{
+					Name:  "gomod",
+					Usage: "Write a go.mod file from the glide.yaml and glide.lock files",
+					Description: `The locked versions are written as the semantic version tagged on the
+   locked commit, or as a pseudo-version when there is no tag. Dependencies
+   fetched from another repository, with the repo setting or a mirror, are
+   written as replace directives. Settings that go.mod can not express are
+   reported.`,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "modules-txt",
+							Usage: "Also write a vendor/modules.txt file for the vendored packages.",
+						},
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "Overwrite an existing go.mod file.",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						action.ExportGomod(c.Bool("modules-txt"), c.Bool("force"))
+						return nil
+					},
+				},
 			},
 		},
 		{
@@ -478,6 +588,14 @@ Example:
 					Name:  "skip-test",
 					Usage: "Resolve dependencies in test files.",
 				},
+				cli.BoolFlag{
+					Name:  "no-local",
+					Usage: "Fail when the glide.lock file has dependencies using a local path.",
+				},
+				cli.BoolFlag{
+					Name:  "prefer-nested-locks",
+					Usage: "Prefer the versions in the glide.lock files of dependencies when choosing the versions of their dependencies.",
+				},
 			},
 			Action: func(c *cli.Context) error {
 				if c.Bool("delete") {
@@ -506,8 +624,9 @@ Example:
 				installer.Force = c.Bool("force")
 				installer.Home = c.GlobalString("home")
 				installer.ResolveTest = !c.Bool("skip-test")
+				installer.PreferNestedLocks = c.Bool("prefer-nested-locks")
 
-				action.Install(installer, c.Bool("strip-vendor"))
+				action.Install(installer, c.Bool("strip-vendor"), c.Bool("no-local"))
 				return nil
 			},
 		},
@@ -515,6 +634,7 @@ Example:
 			Name:      "update",
 			ShortName: "up",
 			Usage:     "Update a project's dependencies",
+			ArgsUsage: "[package...]",
 			Description: `This updates the dependencies by scanning the codebase
    to determine the needed dependencies and fetching them following the rules
    in the glide.yaml file. When no rules exist the tip of the default branch
@@ -535,7 +655,19 @@ Example:
    'Godeps/_workspace' folders after an update (along with undoing any Godep
    import rewriting). Note, the Godeps specific functionality is deprecated and
    will be removed when most Godeps users have migrated to using the vendor
-   folder.`,
+   folder.
+
+   When package names are passed only those packages are updated. Every other
+   dependency is kept at its version in the glide.lock file. New dependencies
+   of the updated packages are added. If the new versions need a kept
+   dependency to change, the update fails and explains why.
+
+       $ glide up github.com/Masterminds/semver
+
+   With '--prefer-nested-locks' the glide.lock file of a dependency is used
+   to choose the versions of its dependencies. The version it locked is used
+   when it satisfies the other requirements on the package, so the versions
+   the dependency was tested with are kept where possible.`,
 			Flags: []cli.Flag{
 				cli.BoolFlag{
 					Name:   "delete",
@@ -596,6 +728,14 @@ Example:
 					Name:  "skip-test",
 					Usage: "Resolve dependencies in test files.",
 				},
+				cli.BoolFlag{
+					Name:  "log",
+					Usage: "Show the commits between the old and new versions of changed Git dependencies.",
+				},
+				cli.BoolFlag{
+					Name:  "prefer-nested-locks",
+					Usage: "Prefer the versions in the glide.lock files of dependencies when choosing the versions of their dependencies.",
+				},
 			},
 			Action: func(c *cli.Context) error {
 				if c.Bool("delete") {
@@ -630,12 +770,106 @@ Example:
 				installer.ResolveAllFiles = c.Bool("all-dependencies")
 				installer.Home = c.GlobalString("home")
 				installer.ResolveTest = !c.Bool("skip-test")
+				installer.PreferNestedLocks = c.Bool("prefer-nested-locks")
 
-				action.Update(installer, c.Bool("no-recursive"), c.Bool("strip-vendor"))
+				action.Update(installer, c.Bool("no-recursive"), c.Bool("strip-vendor"), c.Bool("log"), c.Args())
 
 				return nil
 			},
 		},
+		{
+			Name:  "verify",
+			Usage: "Verify the vendor/ directory matches the glide.lock file",
+			Description: `This compares the packages in the vendor/ directory to the ones
+   listed in the glide.lock file without changing anything. It reports:
+
+   - packages in the lock file missing from vendor/
+   - packages in vendor/ that are not in the lock file
+   - subpackages in the lock file missing from vendor/
+   - revisions that differ from the lock file when VCS metadata is present
+   - local modifications to packages in vendor/
+
+   When any differences are found it exits with a non-zero exit code. This
+   is useful in CI to catch a vendor/ directory that was edited by hand.
+   Use --no-local to also report dependencies using a local path.`,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "no-local",
+					Usage: "Report dependencies using a local path as differences.",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				action.Verify(c.Bool("no-local"))
+				return nil
+			},
+		},
+		{
+			Name:  "outdated",
+			Usage: "Report dependencies with newer versions available",
+			Description: `Outdated compares the glide.yaml and glide.lock files to the versions
+   available in each dependency's repository. For every dependency it shows:
+
+   - The locked version from glide.lock, or the revision when no semantic
+     version tag points to it.
+   - The newest version satisfying the reference in glide.yaml.
+   - The newest semantic version tag overall, ignoring pre-releases.
+
+   Transitive dependencies in glide.lock are included as well. Dependencies
+   missing from glide.lock are shown as not locked rather than outdated.
+
+   The cached repositories are updated to find new versions. Nothing is
+   changed in the project. The exit code is 1 when any dependency is outdated
+   or its versions could not be checked.`,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "Output format. One of: json|json-pretty|text",
+					Value: "text",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				outdated, err := action.Outdated(c.String("output"))
+				if err != nil {
+					msg.Err("%s", err)
+					os.Exit(1)
+				}
+				if outdated {
+					os.Exit(1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "why",
+			Usage:     "Explain why a package is a dependency",
+			ArgsUsage: "<package>",
+			Description: `Why prints every import path from the packages in this project to
+   the given package or any of its subpackages. For example,
+
+       $ glide why github.com/Masterminds/semver
+
+   Chains only used by tests are marked with (test). Dependencies limited to
+   certain operating systems or architectures in glide.yaml or glide.lock
+   have those listed next to them.
+
+   The import graph is built from the vendor/ directory so the dependencies
+   need to be installed first.`,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "all-dependencies",
+					Usage: "Scan all packages of each dependency, not just those directly used.",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if len(c.Args()) < 1 {
+					fmt.Println("Oops! Package name is required.")
+					os.Exit(1)
+				}
+
+				action.Why(c.Args().First(), c.Bool("all-dependencies"))
+				return nil
+			},
+		},
 		{
 			Name:  "tree",
 			Usage: "(Deprecated) Tree prints the dependencies of this project as a tree.",
@@ -727,6 +961,148 @@ Example:
 				return nil
 			},
 		},
+		{
+			Name:  "lock",
+			Usage: "Work with glide.lock files",
+			Subcommands: []cli.Command{
+				{
+					Name:      "diff",
+					Usage:     "Show the changes to dependencies between two lock files",
+					ArgsUsage: "<old> <new>",
+					Description: `Compares two glide.lock files and lists the dependencies that were
+   added, removed, upgraded, downgraded, changed to a version that is neither,
+   or moved to a different repository. This is the same summary 'glide update'
+   displays when it changes the lock file.
+
+   Tags and the order of versions are found using the repositories already in
+   the cache. Nothing is fetched. Run 'glide install' with the new lock file
+   first for the most detail.
+
+   Example:
+
+       $ git show master:glide.lock > /tmp/glide.lock
+       $ glide lock diff /tmp/glide.lock glide.lock`,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "output, o",
+							Usage: "Output format. One of: json|json-pretty|text",
+							Value: "text",
+						},
+						cli.BoolFlag{
+							Name:  "log",
+							Usage: "Show the commits between the old and new versions of changed Git dependencies.",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if len(c.Args()) != 2 {
+							fmt.Println("Oops! The old and new lock files are required.")
+							os.Exit(1)
+						}
+						action.LockDiff(c.Args()[0], c.Args()[1], c.String("output"), c.Bool("log"))
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "cache",
+			Usage: "Inspect and clean up the Glide cache",
+			Subcommands: []cli.Command{
+				{
+					Name:  "list",
+					Usage: "List the repositories in the cache",
+					Description: `Lists each repository in the Glide cache with the key of its directory,
+   the location it was fetched from, its size on disk, and when it was last
+   used to populate a vendor directory.`,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "output, o",
+							Usage: "Output format. One of: json|json-pretty|text",
+							Value: "text",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						action.CacheList(c.String("output"))
+						return nil
+					},
+				},
+				{
+					Name:  "verify",
+					Usage: "Check the repositories in the cache for corruption",
+					Description: `Runs the consistency check of each repository's VCS and compares its
+   remote to the one it was cloned from. This finds repositories left
+   incomplete when Glide was stopped while cloning them. With --repair the
+   corrupt repositories are cloned again. The exit code is 1 when problems
+   remain.`,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "repair",
+							Usage: "Clone corrupt repositories again.",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if action.CacheVerify(c.Bool("repair")) {
+							os.Exit(1)
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "gc",
+					Usage: "Remove unused repositories from the cache",
+					Description: `Removes repositories from the Glide cache that have not been used within
+   a number of days. When a maximum size is set the least recently used
+   repositories are removed until the cache fits. Git repositories that remain
+   are compacted with git gc. The space reclaimed is reported.
+
+   Example:
+
+       $ glide cache gc --keep-days 30 --max-size 2G`,
+					Flags: []cli.Flag{
+						cli.IntFlag{
+							Name:  "keep-days",
+							Usage: "Keep repositories used within this many days. 0 keeps all of them.",
+						},
+						cli.StringFlag{
+							Name:  "max-size",
+							Usage: "The maximum size of the cache, such as 500M or 2G.",
+						},
+						cli.BoolFlag{
+							Name:  "skip-git-gc",
+							Usage: "Do not run git gc on the remaining Git repositories.",
+						},
+						cli.BoolFlag{
+							Name:  "dry-run",
+							Usage: "Report what would be removed without removing anything.",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						action.CacheGC(c.Int("keep-days"), c.String("max-size"), !c.Bool("skip-git-gc"), c.Bool("dry-run"))
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "serve-cache",
+			Usage: "Serve the Glide cache to other instances of Glide",
+			Description: `Serves the repositories in the Glide cache over HTTP using the
+   proxy protocol. Other instances of Glide can use it by setting its URL in the
+   proxy.yaml file in their GLIDE_HOME. Only the repositories already in the cache
+   are served and nothing is fetched. Snapshots are available for Git and
+   Mercurial repositories.`,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "listen",
+					Usage: "The address to listen on.",
+					Value: ":8080",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				action.ServeCache(c.String("listen"))
+				return nil
+			},
+		},
 		{
 			Name:  "about",
 			Usage: "Learn about Glide",
@@ -830,13 +1206,28 @@ func startup(c *cli.Context) error {
 	action.Debug(c.Bool("debug"))
 	action.NoColor(c.Bool("no-color"))
 	action.Quiet(c.Bool("quiet"))
+	action.Offline(c.Bool("offline"))
+	action.Shallow(c.Bool("shallow"))
+	if err := action.Output(c.String("output")); err != nil {
+		return err
+	}
 	action.Init(c.String("yaml"), c.String("home"))
+	if err := action.Concurrency(c.Int("jobs"), c.Int("host-jobs"), c.Float64("fetch-rate")); err != nil {
+		return err
+	}
+	if err := action.Retries(c.Int("retries"), c.Duration("retry-backoff")); err != nil {
+		return err
+	}
+	if err := action.LockTimeout(c.Duration("lock-timeout")); err != nil {
+		return err
+	}
 	action.EnsureGoVendor()
 	gpath.Tmp = c.String("tmp")
 	return nil
 }
 
 func shutdown(c *cli.Context) error {
+	action.Shutdown()
 	cache.SystemUnlock()
 	return nil
 }
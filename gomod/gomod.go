@@ -0,0 +1,304 @@
+// Package gomod provides basic importing of Go module go.mod files.
+//
+// This is not a complete implementation of Go modules. A required version is
+// a minimum, so it becomes a range up to the next major version that Glide
+// solves along with the other requirements rather than selecting the minimal
+// versions across the whole build.
+package gomod
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/util"
+	"github.com/Masterminds/semver"
+)
+
+// Module is a module path and version from a go.mod directive.
+type Module struct {
+	Path    string
+	Version string
+}
+
+// Replace is a replace directive. Old.Version is empty when all versions of
+// the module are replaced. New.Version is empty when New.Path is a local
+// directory.
+type Replace struct {
+	Old Module
+	New Module
+}
+
+// File is the content of a go.mod file used by Glide.
+type File struct {
+	Module  string
+	Require []Module
+	Replace []Replace
+	Exclude []Module
+}
+
+// Has returns true if this dir has a go.mod file.
+func Has(dir string) bool {
+	path := filepath.Join(dir, "go.mod")
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+// Parse parses a go.mod file.
+//
+// Each required module becomes a dependency on the repository it is in. A
+// required version is a minimum and is set as a range up to the next major
+// version. Pseudo-versions and the tags of modules in a subdirectory of their
+// repository can not be used in a range and are set as the VCS reference. A
+// replace directive pointing at another module sets the repository and the
+// exact version instead.
+func Parse(dir string) ([]*cfg.Dependency, error) {
+	path := filepath.Join(dir, "go.mod")
+	if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+		return []*cfg.Dependency{}, nil
+	}
+
+	msg.Info("Found go.mod file in %s", gpath.StripBasepath(dir))
+	msg.Info("--> Parsing go.mod metadata...")
+	buf := []*cfg.Dependency{}
+
+	mf, err := ParseFile(path)
+	if err != nil {
+		return buf, err
+	}
+
+	excluded := map[Module]bool{}
+	for _, e := range mf.Exclude {
+		excluded[e] = true
+	}
+
+	seen := map[string]bool{}
+	for _, r := range mf.Require {
+		m := r
+		pkg := moduleRoot(r.Path)
+		repo := ""
+		replaced := false
+		if rep := mf.replacement(r); rep != nil {
+			if rep.New.Version == "" {
+				msg.Debug("Skipping replacement of %s with the local directory %s", r.Path, rep.New.Path)
+			} else {
+				m = rep.New
+				replaced = true
+				if root := moduleRoot(rep.New.Path); root != pkg {
+					repo = "https://" + root
+				}
+			}
+		}
+
+		if excluded[m] {
+			msg.Warn("%s %s is required but also excluded in %s. Skipping the version", m.Path, m.Version, path)
+			m.Version = ""
+		}
+
+		if seen[pkg] {
+			continue
+		}
+		seen[pkg] = true
+
+		ref := Reference(m.Version)
+		if ref != "" && !pseudoVersion.MatchString(m.Version) {
+			if prefix := tagPrefix(m.Path); prefix != "" {
+				ref = prefix + ref
+			} else if !replaced {
+				ref = minimumRange(ref)
+			}
+		}
+		buf = append(buf, &cfg.Dependency{
+			Name:       pkg,
+			Reference:  ref,
+			Repository: repo,
+		})
+	}
+
+	return buf, nil
+}
+
+// minimumRange returns the range of versions from a minimum version up to the
+// next major version. Other references are returned as they are.
+func minimumRange(ref string) string {
+	v, err := semver.NewVersion(ref)
+	if err != nil {
+		return ref
+	}
+
+	return fmt.Sprintf(">=%s, <v%d.0.0", ref, v.Major()+1)
+}
+
+// tagPrefix returns the prefix of the version tags for a module within a
+// subdirectory of its repository, such as foo/ for the module
+// github.com/example/repo/foo.
+func tagPrefix(path string) string {
+	root := moduleRoot(path)
+	rel := strings.TrimPrefix(strings.TrimPrefix(path, root), "/")
+	if i := strings.LastIndex(rel, "/"); majorSuffix.MatchString(rel[i+1:]) {
+		rel = strings.TrimSuffix(rel[:i+1], "/")
+	}
+	if rel == "" {
+		return ""
+	}
+
+	return rel + "/"
+}
+
+// replacement returns the replace directive that applies to a module.
+func (f *File) replacement(m Module) *Replace {
+	var found *Replace
+	for i, r := range f.Replace {
+		if r.Old.Path != m.Path {
+			continue
+		}
+		// A replacement of a specific version wins over one for all versions.
+		if r.Old.Version == m.Version {
+			return &f.Replace[i]
+		}
+		if r.Old.Version == "" {
+			found = &f.Replace[i]
+		}
+	}
+
+	return found
+}
+
+// moduleRoot returns the repository root for a module path. The major version
+// suffix of a module, such as /v2, is not part of the repository.
+func moduleRoot(path string) string {
+	root := util.GetRootFromPackage(path)
+	if root == path {
+		if i := strings.LastIndex(path, "/"); i != -1 && majorSuffix.MatchString(path[i+1:]) {
+			root = util.GetRootFromPackage(path[:i])
+		}
+	}
+
+	return root
+}
+
+var majorSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// pseudoVersion matches the forms of a module pseudo-version:
+// vX.0.0-yyyymmddhhmmss-abcdefabcdef, vX.Y.Z-pre.0.yyyymmddhhmmss-abcdefabcdef
+// and vX.Y.(Z+1)-0.yyyymmddhhmmss-abcdefabcdef.
+var pseudoVersion = regexp.MustCompile(`^v[0-9]+\.[0-9]+\.[0-9]+-(?:.*[.-])?[0-9]{14}-([0-9a-f]{12,})(?:\+incompatible)?$`)
+
+// Reference converts a module version to a VCS reference. A pseudo-version
+// becomes the commit id it contains and the +incompatible suffix is removed
+// from the tag name.
+func Reference(version string) string {
+	if m := pseudoVersion.FindStringSubmatch(version); m != nil {
+		return m[1]
+	}
+
+	return strings.TrimSuffix(version, "+incompatible")
+}
+
+// ParseFile reads the module, require, replace and exclude directives from a
+// go.mod file.
+func ParseFile(path string) (*File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	f := &File{}
+	block := ""
+	s := bufio.NewScanner(file)
+	n := 0
+	for s.Scan() {
+		n++
+		line := s.Text()
+		if i := strings.Index(line, "//"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		verb := block
+		if block == "" {
+			verb = fields[0]
+			fields = fields[1:]
+			if len(fields) == 1 && fields[0] == "(" {
+				block = verb
+				continue
+			}
+		} else if fields[0] == ")" {
+			block = ""
+			continue
+		}
+
+		for i := range fields {
+			if uq, err := strconv.Unquote(fields[i]); err == nil {
+				fields[i] = uq
+			}
+		}
+
+		switch verb {
+		case "module":
+			if len(fields) != 1 {
+				return nil, fmt.Errorf("%s:%d: invalid module directive", path, n)
+			}
+			f.Module = fields[0]
+		case "require", "exclude":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("%s:%d: invalid %s directive", path, n, verb)
+			}
+			m := Module{Path: fields[0], Version: fields[1]}
+			if verb == "require" {
+				f.Require = append(f.Require, m)
+			} else {
+				f.Exclude = append(f.Exclude, m)
+			}
+		case "replace":
+			r, err := parseReplace(fields)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %s", path, n, err)
+			}
+			f.Replace = append(f.Replace, r)
+		}
+	}
+
+	return f, s.Err()
+}
+
+func parseReplace(fields []string) (Replace, error) {
+	r := Replace{}
+	arrow := -1
+	for i, f := range fields {
+		if f == "=>" {
+			arrow = i
+		}
+	}
+
+	switch arrow {
+	case 1:
+		r.Old = Module{Path: fields[0]}
+	case 2:
+		r.Old = Module{Path: fields[0], Version: fields[1]}
+	default:
+		return r, fmt.Errorf("invalid replace directive")
+	}
+
+	switch len(fields) - arrow - 1 {
+	case 1:
+		r.New = Module{Path: fields[arrow+1]}
+	case 2:
+		r.New = Module{Path: fields[arrow+1], Version: fields[arrow+2]}
+	default:
+		return r, fmt.Errorf("invalid replace directive")
+	}
+
+	return r, nil
+}
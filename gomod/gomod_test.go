@@ -0,0 +1,150 @@
+package gomod
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		gomod string
+		want  map[string][2]string
+	}{
+		{
+			name: "require",
+			gomod: `module github.com/example/app
+
+go 1.12
+
+require (
+	github.com/example/a v1.2.0
+	github.com/example/b v0.3.1 // indirect
+	"github.com/example/c" v0.0.0-20190101120000-abcdef123456
+	github.com/example/d/v2 v2.1.0
+	github.com/example/e v3.0.0+incompatible
+	github.com/example/g v1.3.0-rc.1.0.20190101120000-0123456789ab
+)
+
+require github.com/example/f v1.0.0 // indirect
+`,
+			want: map[string][2]string{
+				"github.com/example/a": {">=v1.2.0, <v2.0.0", ""},
+				"github.com/example/b": {">=v0.3.1, <v1.0.0", ""},
+				"github.com/example/c": {"abcdef123456", ""},
+				"github.com/example/d": {">=v2.1.0, <v3.0.0", ""},
+				"github.com/example/e": {">=v3.0.0, <v4.0.0", ""},
+				"github.com/example/f": {">=v1.0.0, <v2.0.0", ""},
+				"github.com/example/g": {"0123456789ab", ""},
+			},
+		},
+		{
+			name: "replace",
+			gomod: `module github.com/example/app
+
+require (
+	github.com/example/a v1.2.0
+	github.com/example/b v1.0.0
+	github.com/example/c v1.0.0
+)
+
+replace github.com/example/a => github.com/fork/a v1.2.1
+
+replace github.com/example/b v1.0.0 => ../b
+
+replace (
+	github.com/example/c v0.9.0 => github.com/example/c v0.9.1
+	github.com/example/c v1.0.0 => github.com/example/c v1.0.5
+)
+`,
+			want: map[string][2]string{
+				"github.com/example/a": {"v1.2.1", "https://github.com/fork/a"},
+				"github.com/example/b": {">=v1.0.0, <v2.0.0", ""},
+				"github.com/example/c": {"v1.0.5", ""},
+			},
+		},
+		{
+			name: "subdirectory and exclude",
+			gomod: `module github.com/example/app
+
+require (
+	github.com/example/repo/foo v1.2.0
+	github.com/example/repo/bar/v2 v2.0.0
+	github.com/example/x v1.1.0
+)
+
+exclude github.com/example/x v1.1.0
+`,
+			want: map[string][2]string{
+				"github.com/example/repo": {"foo/v1.2.0", ""},
+				"github.com/example/x":    {"", ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dir, err := ioutil.TempDir("", "glide-gomod-test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(tt.gomod), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		deps, err := Parse(dir)
+		if err != nil {
+			t.Errorf("%s: Unexpected error parsing: %s", tt.name, err)
+			continue
+		}
+		if len(deps) != len(tt.want) {
+			t.Errorf("%s: Expected %d dependencies but got %d", tt.name, len(tt.want), len(deps))
+		}
+		for _, d := range deps {
+			want, ok := tt.want[d.Name]
+			if !ok {
+				t.Errorf("%s: Unexpected dependency %s", tt.name, d.Name)
+				continue
+			}
+			if d.Reference != want[0] || d.Repository != want[1] {
+				t.Errorf("%s: Expected %s at %q from %q but got %q from %q", tt.name, d.Name, want[0], want[1], d.Reference, d.Repository)
+			}
+			if strings.HasPrefix(d.Reference, ">=") {
+				if _, err := semver.NewConstraint(d.Reference); err != nil {
+					t.Errorf("%s: Invalid range %q for %s: %s", tt.name, d.Reference, d.Name, err)
+				}
+			}
+		}
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	tests := []string{
+		"module\n",
+		"require github.com/example/a\n",
+		"require (\n\tgithub.com/example/a v1.0.0 extra\n)\n",
+		"replace github.com/example/a v1.0.0\n",
+		"replace github.com/example/a => \n",
+	}
+
+	for _, tt := range tests {
+		dir, err := ioutil.TempDir("", "glide-gomod-test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		p := filepath.Join(dir, "go.mod")
+		if err := ioutil.WriteFile(p, []byte(tt), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := ParseFile(p); err == nil {
+			t.Errorf("Expected an error parsing %q", tt)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package gomod
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Format returns the go.mod file content for the module, require, replace and
+// exclude directives.
+func (f *File) Format() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "module %s\n", f.Module)
+
+	writeBlock(&b, "require", modLines(f.Require))
+
+	var rl []string
+	for _, r := range f.Replace {
+		rl = append(rl, strings.TrimSpace(r.Old.Path+" "+r.Old.Version)+" => "+strings.TrimSpace(r.New.Path+" "+r.New.Version))
+	}
+	writeBlock(&b, "replace", rl)
+
+	writeBlock(&b, "exclude", modLines(f.Exclude))
+
+	return b.Bytes()
+}
+
+func modLines(mods []Module) []string {
+	var l []string
+	for _, m := range mods {
+		l = append(l, m.Path+" "+m.Version)
+	}
+	return l
+}
+
+func writeBlock(b *bytes.Buffer, verb string, lines []string) {
+	switch len(lines) {
+	case 0:
+		return
+	case 1:
+		fmt.Fprintf(b, "\n%s %s\n", verb, lines[0])
+	default:
+		sort.Strings(lines)
+		fmt.Fprintf(b, "\n%s (\n", verb)
+		for _, l := range lines {
+			fmt.Fprintf(b, "\t%s\n", l)
+		}
+		b.WriteString(")\n")
+	}
+}
+
+// gopkgMajor matches the major version of a gopkg.in path such as
+// gopkg.in/yaml.v2.
+var gopkgMajor = regexp.MustCompile(`^gopkg\.in/(?:[^/]+/)?[^/]+\.(v[0-9]+)(?:-unstable)?$`)
+
+// Major returns the major version for a module path, such as v2 for
+// github.com/example/foo/v2 or gopkg.in/yaml.v2. Other paths are v0 or v1 and
+// an empty string is returned.
+func Major(path string) string {
+	if m := gopkgMajor.FindStringSubmatch(path); m != nil {
+		return m[1]
+	}
+	if i := strings.LastIndex(path, "/"); i != -1 && majorSuffix.MatchString(path[i+1:]) {
+		return path[i+1:]
+	}
+
+	return ""
+}
+
+// PseudoVersion returns the pseudo-version for a commit that is not tagged.
+func PseudoVersion(path string, t time.Time, rev string) string {
+	major := Major(path)
+	if major == "" || major == "v1" {
+		major = "v0"
+	}
+	if len(rev) > 12 {
+		rev = rev[:12]
+	}
+
+	return fmt.Sprintf("%s.0.0-%s-%s", major, t.UTC().Format("20060102150405"), rev)
+}
+
+// TagVersion returns the module version for a semantic version tag. A tag with
+// a major version of 2 or higher for a module path without a major version is
+// marked as +incompatible.
+func TagVersion(path, tag string) string {
+	if !strings.HasPrefix(tag, "v") {
+		tag = "v" + tag
+	}
+
+	i := strings.Index(tag, ".")
+	if i == -1 {
+		return tag
+	}
+	major := tag[:i]
+	if major != "v0" && major != "v1" && Major(path) != major {
+		return tag + "+incompatible"
+	}
+
+	return tag
+}
+
+var scpSyntax = regexp.MustCompile(`^([a-zA-Z0-9_]+)@([a-zA-Z0-9._-]+):(.*)$`)
+
+// ModulePath converts a repository location, such as https://github.com/foo/bar
+// or git@github.com:foo/bar.git, to the module path for it.
+func ModulePath(remote string) (string, error) {
+	var host, pth string
+	if m := scpSyntax.FindStringSubmatch(remote); m != nil {
+		host, pth = m[2], m[3]
+	} else {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return "", err
+		}
+		if u.Host == "" || u.Scheme == "file" {
+			return "", fmt.Errorf("%s is not a remote repository", remote)
+		}
+		host, pth = u.Host, u.Path
+	}
+
+	pth = strings.TrimSuffix(strings.Trim(pth, "/"), ".git")
+	if pth == "" {
+		return "", fmt.Errorf("%s has no repository path", remote)
+	}
+
+	return host + "/" + pth, nil
+}
@@ -0,0 +1,177 @@
+// Package govendor provides basic importing of govendor's vendor.json file.
+//
+// This is not a complete implementation of govendor.
+package govendor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/util"
+)
+
+// VendorFile is the structure of govendor's vendor.json file. Only the fields
+// used by Glide are included.
+type VendorFile struct {
+	RootPath string     `json:"rootPath"`
+	Package  []*Package `json:"package"`
+}
+
+// Package is a package entry in a vendor.json file.
+type Package struct {
+	Path         string `json:"path"`
+	Origin       string `json:"origin,omitempty"`
+	Revision     string `json:"revision"`
+	RevisionTime string `json:"revisionTime,omitempty"`
+	Version      string `json:"version,omitempty"`
+	VersionExact string `json:"versionExact,omitempty"`
+	Tree         bool   `json:"tree,omitempty"`
+}
+
+// reference returns the exact version tag of a package, when govendor matched
+// one, or its revision.
+func (p *Package) reference() string {
+	if p.VersionExact != "" {
+		return p.VersionExact
+	}
+
+	return p.Revision
+}
+
+// Has returns true if this dir has a govendor vendor.json file.
+func Has(dir string) bool {
+	path := filepath.Join(dir, "vendor/vendor.json")
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+// Parse parses govendor's vendor.json file.
+//
+// The package entries are merged into their repository roots with the other
+// packages recorded as subpackages. The exact version tag govendor matched is
+// used over the revision. When packages from the same repository are at
+// different revisions the most recent one, by revision time, is used.
+func Parse(dir string) ([]*cfg.Dependency, error) {
+	path := filepath.Join(dir, "vendor/vendor.json")
+	if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+		return []*cfg.Dependency{}, nil
+	}
+
+	msg.Info("Found govendor file in %s", gpath.StripBasepath(dir))
+	msg.Info("--> Parsing govendor metadata...")
+	buf := []*cfg.Dependency{}
+	file, err := os.Open(path)
+	if err != nil {
+		return buf, err
+	}
+	defer file.Close()
+
+	vf := VendorFile{}
+	dec := json.NewDecoder(file)
+	if err := dec.Decode(&vf); err != nil {
+		return buf, err
+	}
+
+	seen := map[string]*cfg.Dependency{}
+	times := map[string]time.Time{}
+	trees := map[string][]string{}
+	for _, p := range vf.Package {
+		if p.Path == "" {
+			continue
+		}
+		pkg := util.GetRootFromPackage(p.Path)
+		sub := strings.TrimPrefix(strings.TrimPrefix(p.Path, pkg), "/")
+		rt, _ := time.Parse(time.RFC3339, p.RevisionTime)
+		ref := p.reference()
+
+		dep, ok := seen[pkg]
+		if !ok {
+			dep = &cfg.Dependency{
+				Name:       pkg,
+				Reference:  ref,
+				Repository: originRepo(p, pkg, sub),
+			}
+			seen[pkg] = dep
+			times[pkg] = rt
+			buf = append(buf, dep)
+		} else if ref != "" && ref != dep.Reference {
+			if dep.Reference == "" {
+				dep.Reference = ref
+				times[pkg] = rt
+			} else if rt.After(times[pkg]) {
+				msg.Warn("govendor lists %s at revisions %s and %s. Using %s", pkg, dep.Reference, ref, ref)
+				dep.Reference = ref
+				times[pkg] = rt
+			} else {
+				msg.Warn("govendor lists %s at revisions %s and %s. Using %s", pkg, dep.Reference, ref, dep.Reference)
+			}
+		}
+
+		if p.Tree {
+			trees[pkg] = append(trees[pkg], sub)
+		}
+		if sub != "" && !dep.HasSubpackage(sub) {
+			dep.Subpackages = append(dep.Subpackages, sub)
+		}
+	}
+
+	// A tree entry includes every package below it so listing the root of
+	// the tree is enough.
+	for _, dep := range buf {
+		if len(trees[dep.Name]) > 0 {
+			dep.Subpackages = treeSubpackages(dep.Subpackages, trees[dep.Name])
+		}
+	}
+
+	return buf, nil
+}
+
+// treeSubpackages removes the subpackages within one of the trees.
+func treeSubpackages(subs, trees []string) []string {
+	n := []string{}
+	for _, s := range subs {
+		covered := false
+		for _, t := range trees {
+			if t == "" || strings.HasPrefix(s, t+"/") {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			n = append(n, s)
+		}
+	}
+
+	return n
+}
+
+// originRepo returns the repository a package was copied from when it has an
+// origin other than its path.
+func originRepo(p *Package, pkg, sub string) string {
+	if p.Origin == "" || p.Origin == p.Path {
+		return ""
+	}
+
+	// The origin may be a copy within the vendor directory of another
+	// project which is not something Glide can fetch from.
+	if strings.Contains(p.Origin, "/vendor/") {
+		msg.Debug("Skipping origin %s of %s as it is within a vendor directory", p.Origin, p.Path)
+		return ""
+	}
+
+	root := strings.TrimSuffix(p.Origin, "/"+sub)
+	if sub == "" || root == p.Origin {
+		root = util.GetRootFromPackage(p.Origin)
+	}
+	if root == pkg {
+		return ""
+	}
+
+	return "https://" + root
+}
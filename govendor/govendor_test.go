@@ -0,0 +1,65 @@
+package govendor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	deps, err := Parse("../testdata/govendor")
+	if err != nil {
+		t.Fatalf("Unexpected error parsing: %s", err)
+	}
+
+	tests := []struct {
+		name, ref, repo string
+		subs            []string
+	}{
+		{name: "github.com/Masterminds/semver", ref: "v1.2.2"},
+		{name: "github.com/golang/protobuf", ref: "1f49d83d9aa00e6ce4fc8258c71cc7786aec968a", subs: []string{"proto", "ptypes/any", "ptypes"}},
+		{name: "github.com/gorilla/mux", ref: "392c28fe23e1c45ddba891b0320b3b5df220beea"},
+		{name: "github.com/pkg/errors", ref: "master", repo: "https://github.com/someone/errors"},
+		{name: "github.com/example/copied", ref: "a8c1b0d2e3f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8"},
+	}
+	if len(deps) != len(tests) {
+		t.Fatalf("Expected %d dependencies but got %d", len(tests), len(deps))
+	}
+	for i, tt := range tests {
+		d := deps[i]
+		if d.Name != tt.name {
+			t.Errorf("Expected dependency %s but got %s", tt.name, d.Name)
+			continue
+		}
+		if d.Reference != tt.ref || d.Repository != tt.repo {
+			t.Errorf("Expected %s at %q from %q but got %q from %q", d.Name, tt.ref, tt.repo, d.Reference, d.Repository)
+		}
+		if len(d.Subpackages) != len(tt.subs) || (len(tt.subs) > 0 && !reflect.DeepEqual(d.Subpackages, tt.subs)) {
+			t.Errorf("Expected the subpackages %v of %s but got %v", tt.subs, d.Name, d.Subpackages)
+		}
+	}
+}
+
+func TestTreeSubpackages(t *testing.T) {
+	tests := []struct {
+		subs, trees, want []string
+	}{
+		{[]string{"a", "a/b", "c"}, []string{"a"}, []string{"a", "c"}},
+		{[]string{"", "a", "b/c"}, []string{""}, []string{}},
+		{[]string{"ab", "a/b"}, []string{"a"}, []string{"ab"}},
+	}
+
+	for _, tt := range tests {
+		if got := treeSubpackages(tt.subs, tt.trees); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expected %v for the trees %v of %v but got %v", tt.want, tt.trees, tt.subs, got)
+		}
+	}
+}
+
+func TestHas(t *testing.T) {
+	if !Has("../testdata/govendor") {
+		t.Error("Expected a vendor.json file to be found")
+	}
+	if Has("../testdata/name") {
+		t.Error("Expected no vendor.json file")
+	}
+}
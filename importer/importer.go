@@ -1,4 +1,4 @@
-// Package importer imports dependency configuration from Glide, Godep, GPM, GB and gom
+// Package importer imports dependency configuration from Glide, Godep, GPM, GB, gom, dep, govendor and Go modules
 package importer
 
 import (
@@ -7,15 +7,18 @@ import (
 	"path/filepath"
 
 	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dep"
 	"github.com/Masterminds/glide/gb"
 	"github.com/Masterminds/glide/godep"
 	"github.com/Masterminds/glide/gom"
+	"github.com/Masterminds/glide/gomod"
+	"github.com/Masterminds/glide/govendor"
 	"github.com/Masterminds/glide/gpm"
 )
 
 var i = &DefaultImporter{}
 
-// Import uses the DefaultImporter to import from Glide, Godep, GPM, GB and gom.
+// Import uses the DefaultImporter to import from Glide, Godep, GPM, GB, gom, dep, govendor and Go modules.
 func Import(path string) (bool, []*cfg.Dependency, error) {
 	return i.Import(path)
 }
@@ -30,10 +33,10 @@ type Importer interface {
 	Import(path string) (bool, []*cfg.Dependency, error)
 }
 
-// DefaultImporter imports from Glide, Godep, GPM, GB and gom.
+// DefaultImporter imports from Glide, Godep, GPM, GB, gom, dep, govendor and Go modules.
 type DefaultImporter struct{}
 
-// Import tries to import configuration from Glide, Godep, GPM, GB and gom.
+// Import tries to import configuration from Glide, Godep, GPM, GB, gom, dep, govendor and Go modules.
 func (d *DefaultImporter) Import(path string) (bool, []*cfg.Dependency, error) {
 
 	// Try importing from Glide first.
@@ -87,6 +90,33 @@ func (d *DefaultImporter) Import(path string) (bool, []*cfg.Dependency, error) {
 		return true, deps, nil
 	}
 
+	// Try importing from dep
+	if dep.Has(path) {
+		deps, err := dep.Parse(path)
+		if err != nil {
+			return false, []*cfg.Dependency{}, err
+		}
+		return true, deps, nil
+	}
+
+	// Try importing from govendor
+	if govendor.Has(path) {
+		deps, err := govendor.Parse(path)
+		if err != nil {
+			return false, []*cfg.Dependency{}, err
+		}
+		return true, deps, nil
+	}
+
+	// Try importing from Go modules
+	if gomod.Has(path) {
+		deps, err := gomod.Parse(path)
+		if err != nil {
+			return false, []*cfg.Dependency{}, err
+		}
+		return true, deps, nil
+	}
+
 	// When none are found.
 	return false, []*cfg.Dependency{}, nil
 }
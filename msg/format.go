@@ -0,0 +1,149 @@
+package msg
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/vcs"
+)
+
+// The levels of log messages.
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Fields are structured details attached to a log message. Commonly used keys
+// are "package", "version", "action" and "error".
+type Fields map[string]interface{}
+
+// Entry is a single log message passed to a Formatter.
+type Entry struct {
+	Level   string
+	Time    time.Time
+	Message string
+	Fields  Fields
+}
+
+// Formatter formats log messages. When a Messenger has a Formatter the log
+// levels (Debug, Info, Warn, Err and Die) are written to Stderr using it
+// instead of the colored text output.
+type Formatter interface {
+	Format(e *Entry) ([]byte, error)
+}
+
+// JSONFormatter formats each message as a JSON object on its own line. The
+// fields are included alongside the level, time and message.
+type JSONFormatter struct{}
+
+// Format returns the JSON object for an entry.
+func (j *JSONFormatter) Format(e *Entry) ([]byte, error) {
+	o := make(map[string]interface{}, len(e.Fields)+3)
+	for k, v := range e.Fields {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		o[k] = v
+	}
+	o["level"] = e.Level
+	o["time"] = e.Time.Format(time.RFC3339Nano)
+	o["message"] = e.Message
+
+	b, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+// Logger writes log messages with fields attached using a Messenger.
+type Logger struct {
+	m      *Messenger
+	fields Fields
+}
+
+// With returns a Logger that attaches the fields to its messages. The fields
+// are only displayed by a Formatter.
+func (m *Messenger) With(f Fields) *Logger {
+	return &Logger{m: m, fields: f}
+}
+
+// With returns a Logger that attaches the fields to messages logged with the
+// Default Messenger.
+func With(f Fields) *Logger {
+	return Default.With(f)
+}
+
+// Info logs information
+func (l *Logger) Info(msg string, args ...interface{}) {
+	l.m.info(l.fields, msg, args...)
+}
+
+// Debug logs debug information
+func (l *Logger) Debug(msg string, args ...interface{}) {
+	l.m.debug(l.fields, msg, args...)
+}
+
+// Warn logs a warning
+func (l *Logger) Warn(msg string, args ...interface{}) {
+	l.m.warn(l.fields, msg, args...)
+}
+
+// Err logs an error.
+func (l *Logger) Err(msg string, args ...interface{}) {
+	l.m.err(l.fields, msg, args...)
+}
+
+// Die logs an error and immediately exits the application.
+func (l *Logger) Die(msg string, args ...interface{}) {
+	l.m.die(l.fields, msg, args...)
+}
+
+// format writes a log message using the Formatter.
+func (m *Messenger) format(level string, fields Fields, msg string, args ...interface{}) {
+	e := &Entry{
+		Level:   level,
+		Time:    time.Now(),
+		Message: msg,
+		Fields:  Fields{},
+	}
+	if len(args) > 0 {
+		e.Message = fmt.Sprintf(msg, args...)
+	}
+	e.Message = strings.TrimSpace(e.Message)
+	for k, v := range fields {
+		e.Fields[k] = v
+	}
+
+	// An error passed as the last argument is included as a field along with
+	// the VCS output when debugging.
+	if len(args) > 0 {
+		if err, ok := args[len(args)-1].(error); ok {
+			if _, ok := e.Fields["error"]; !ok {
+				e.Fields["error"] = err.Error()
+			}
+			if !m.Quiet && m.IsDebugging {
+				switch t := err.(type) {
+				case *vcs.LocalError:
+					e.Fields["output"] = strings.TrimSpace(t.Out())
+				case *vcs.RemoteError:
+					e.Fields["output"] = strings.TrimSpace(t.Out())
+				}
+			}
+		}
+	}
+
+	b, err := m.Formatter.Format(e)
+	if err != nil {
+		b = []byte(fmt.Sprintf("Unable to format message %q: %s\n", e.Message, err))
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	m.Stderr.Write(b)
+}
@@ -0,0 +1,74 @@
+package msg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	var out bytes.Buffer
+	m := NewMessenger()
+	m.Stderr = &out
+	m.Formatter = &JSONFormatter{}
+
+	m.Info("Fetching %s", "github.com/example/foo")
+	m.Warn("Version %s is not valid\n", "^x")
+	m.Err("Unable to export %s: %s", "github.com/example/foo", errors.New("permission denied"))
+	m.With(Fields{"package": "github.com/example/bar", "version": "v1.0.0", "action": "setting version"}).Info("--> Setting version")
+	m.With(Fields{"error": errors.New("boom")}).Warn("Failed")
+	m.Debug("Not shown without debugging")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 lines but got %d: %s", len(lines), out.String())
+	}
+
+	tests := []map[string]string{
+		{"level": "info", "message": "Fetching github.com/example/foo"},
+		{"level": "warn", "message": "Version ^x is not valid"},
+		{"level": "error", "message": "Unable to export github.com/example/foo: permission denied", "error": "permission denied"},
+		{"level": "info", "message": "--> Setting version", "package": "github.com/example/bar", "version": "v1.0.0", "action": "setting version"},
+		{"level": "warn", "message": "Failed", "error": "boom"},
+	}
+	for i, want := range tests {
+		got := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(lines[i]), &got); err != nil {
+			t.Errorf("Line %d is not JSON: %s", i, err)
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339Nano, got["time"].(string)); err != nil {
+			t.Errorf("Line %d has an invalid time: %s", i, err)
+		}
+		delete(got, "time")
+		if len(got) != len(want) {
+			t.Errorf("Line %d: Expected the keys %v but got %v", i, want, got)
+		}
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("Line %d: Expected %s to be %q but got %v", i, k, v, got[k])
+			}
+		}
+	}
+	if !m.HasErrored() {
+		t.Error("Expected an error to be recorded")
+	}
+}
+
+func TestJSONFormatterQuiet(t *testing.T) {
+	var out bytes.Buffer
+	m := NewMessenger()
+	m.Stderr = &out
+	m.Formatter = &JSONFormatter{}
+	m.Quiet = true
+
+	m.Info("hidden")
+	m.With(Fields{"package": "foo"}).Info("hidden")
+	m.Warn("shown")
+	if n := strings.Count(out.String(), "\n"); n != 1 || !strings.Contains(out.String(), `"message":"shown"`) {
+		t.Errorf("Expected only the warning when quiet but got %s", out.String())
+	}
+}
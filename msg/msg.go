@@ -37,6 +37,10 @@ type Messenger struct {
 	// PanicOnDie if true Die() will panic instead of exiting.
 	PanicOnDie bool
 
+	// Formatter, if set, formats the log messages instead of the default
+	// text output.
+	Formatter Formatter
+
 	// The default exit code to use when dyping
 	ecode int
 
@@ -65,9 +69,17 @@ var Default = NewMessenger()
 
 // Info logs information
 func (m *Messenger) Info(msg string, args ...interface{}) {
+	m.info(nil, msg, args...)
+}
+
+func (m *Messenger) info(f Fields, msg string, args ...interface{}) {
 	if m.Quiet {
 		return
 	}
+	if m.Formatter != nil {
+		m.format(LevelInfo, f, msg, args...)
+		return
+	}
 	prefix := m.Color(Green, "[INFO]\t")
 	m.Msg(prefix+msg, args...)
 }
@@ -79,9 +91,17 @@ func Info(msg string, args ...interface{}) {
 
 // Debug logs debug information
 func (m *Messenger) Debug(msg string, args ...interface{}) {
+	m.debug(nil, msg, args...)
+}
+
+func (m *Messenger) debug(f Fields, msg string, args ...interface{}) {
 	if m.Quiet || !m.IsDebugging {
 		return
 	}
+	if m.Formatter != nil {
+		m.format(LevelDebug, f, msg, args...)
+		return
+	}
 	prefix := "[DEBUG]\t"
 	m.Msg(prefix+msg, args...)
 }
@@ -93,6 +113,14 @@ func Debug(msg string, args ...interface{}) {
 
 // Warn logs a warning
 func (m *Messenger) Warn(msg string, args ...interface{}) {
+	m.warn(nil, msg, args...)
+}
+
+func (m *Messenger) warn(f Fields, msg string, args ...interface{}) {
+	if m.Formatter != nil {
+		m.format(LevelWarn, f, msg, args...)
+		return
+	}
 	prefix := m.Color(Yellow, "[WARN]\t")
 	m.Msg(prefix+msg, args...)
 }
@@ -104,8 +132,16 @@ func Warn(msg string, args ...interface{}) {
 
 // Err logs an error.
 func (m *Messenger) Err(msg string, args ...interface{}) {
-	prefix := m.Color(Red, "[ERROR]\t")
-	m.Msg(prefix+msg, args...)
+	m.err(nil, msg, args...)
+}
+
+func (m *Messenger) err(f Fields, msg string, args ...interface{}) {
+	if m.Formatter != nil {
+		m.format(LevelError, f, msg, args...)
+	} else {
+		prefix := m.Color(Red, "[ERROR]\t")
+		m.Msg(prefix+msg, args...)
+	}
 	m.hasErrored = true
 }
 
@@ -118,7 +154,11 @@ func Err(msg string, args ...interface{}) {
 // If PanicOnDie is set to true a panic will occur instead of os.Exit being
 // called.
 func (m *Messenger) Die(msg string, args ...interface{}) {
-	m.Err(msg, args...)
+	m.die(nil, msg, args...)
+}
+
+func (m *Messenger) die(f Fields, msg string, args ...interface{}) {
+	m.err(f, msg, args...)
 	if m.PanicOnDie {
 		panic("trapped a Die() call")
 	}
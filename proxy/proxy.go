@@ -0,0 +1,231 @@
+// Package proxy fetches snapshots of dependencies from an HTTP artifact server
+// instead of cloning their repositories.
+//
+// A proxy is configured in the proxy.yaml file in the Glide home directory.
+// It serves the following paths below its URL:
+//
+//	<package>/@v/list          the versions of a package, one per line
+//	<package>/@v/<ref>.info    the revision of a version, branch, or commit id
+//	<package>/@latest          the revision of the default branch
+//	<package>/@v/<rev>.zip     a zip archive of the files at a revision
+//
+// Info responses are JSON objects with Version, Revision, and Time properties.
+// A 404 or 410 response means the package is not served by the proxy.
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"gopkg.in/yaml.v2"
+)
+
+// ErrNotFound is returned when a package or version is not served by the
+// proxy.
+var ErrNotFound = errors.New("Not found on the proxy")
+
+// Proxy contains the global proxy configuration.
+type Proxy struct {
+
+	// URL is the location of the proxy.
+	URL string `yaml:"url"`
+
+	// Exclude lists package name prefixes that are always fetched from their
+	// repositories.
+	Exclude []string `yaml:"exclude,omitempty"`
+}
+
+// Info describes the revision of a version.
+type Info struct {
+	Version  string    `json:"Version"`
+	Revision string    `json:"Revision"`
+	Time     time.Time `json:"Time"`
+}
+
+var (
+	proxy *Proxy
+
+	lock  sync.Mutex
+	infos = make(map[string]*Info)
+)
+
+// Load reads the proxy configuration into memory.
+func Load() error {
+	op := filepath.Join(gpath.Home(), "proxy.yaml")
+	if _, err := os.Stat(op); os.IsNotExist(err) {
+		msg.Debug("No proxy.yaml file exists")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	yml, err := ioutil.ReadFile(op)
+	if err != nil {
+		return err
+	}
+	return loadFromYaml(yml)
+}
+
+func loadFromYaml(yml []byte) error {
+	p := &Proxy{}
+	if err := yaml.Unmarshal(yml, p); err != nil {
+		return err
+	}
+	if p.URL == "" {
+		proxy = nil
+		return nil
+	}
+
+	p.URL = strings.TrimSuffix(p.URL, "/")
+	msg.Debug("Found proxy: %s", p.URL)
+	proxy = p
+	return nil
+}
+
+// For returns the URL of the proxy serving a package. The bool is false when
+// no proxy is used for it.
+func For(name string) (string, bool) {
+	if proxy == nil {
+		return "", false
+	}
+	for _, e := range proxy.Exclude {
+		if name == e || strings.HasPrefix(name, strings.TrimSuffix(e, "/")+"/") {
+			return "", false
+		}
+	}
+
+	return proxy.URL, true
+}
+
+// Versions lists the versions of a package on a proxy.
+func Versions(base, name string) ([]string, error) {
+	b, err := get(base + "/" + name + "/@v/list")
+	if err != nil {
+		return nil, err
+	}
+
+	var vers []string
+	for _, l := range strings.Split(string(b), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			vers = append(vers, l)
+		}
+	}
+
+	return vers, nil
+}
+
+// GetInfo returns the revision of a version, branch, or commit id of a
+// package on a proxy. An empty ref is the default branch. Responses are kept
+// for the rest of the run.
+func GetInfo(base, name, ref string) (*Info, error) {
+	k := base + "|" + name + "@" + ref
+	lock.Lock()
+	i, ok := infos[k]
+	lock.Unlock()
+	if ok {
+		return i, nil
+	}
+
+	u := base + "/" + name + "/@latest"
+	if ref != "" {
+		u = base + "/" + name + "/@v/" + url.PathEscape(ref) + ".info"
+	}
+	b, err := get(u)
+	if err != nil {
+		return nil, err
+	}
+	i = &Info{}
+	if err := json.Unmarshal(b, i); err != nil {
+		return nil, fmt.Errorf("Invalid response from %s: %s", u, err)
+	}
+	if i.Revision == "" {
+		return nil, fmt.Errorf("Invalid response from %s: no revision", u)
+	}
+
+	lock.Lock()
+	infos[k] = i
+	lock.Unlock()
+	return i, nil
+}
+
+// The kinds of requests in the proxy protocol.
+const (
+	ListRequest   = "list"
+	InfoRequest   = "info"
+	LatestRequest = "latest"
+	ZipRequest    = "zip"
+)
+
+// Request is a request to a proxy for a package.
+type Request struct {
+	Name string
+
+	// Kind is one of ListRequest, InfoRequest, LatestRequest, or ZipRequest.
+	Kind string
+
+	// Ref is the version, branch, or commit id for an InfoRequest and the
+	// revision for a ZipRequest.
+	Ref string
+}
+
+// ParsePath reads a request from the path of a URL on a proxy.
+func ParsePath(p string) (*Request, error) {
+	p = strings.TrimPrefix(p, "/")
+	if strings.HasSuffix(p, "/@latest") {
+		return &Request{Name: strings.TrimSuffix(p, "/@latest"), Kind: LatestRequest}, nil
+	}
+
+	i := strings.LastIndex(p, "/@v/")
+	if i <= 0 {
+		return nil, fmt.Errorf("Invalid proxy path %s", p)
+	}
+	r := &Request{Name: p[:i]}
+	f := p[i+len("/@v/"):]
+	switch {
+	case f == "list":
+		r.Kind = ListRequest
+	case strings.HasSuffix(f, ".info"):
+		r.Kind, r.Ref = InfoRequest, strings.TrimSuffix(f, ".info")
+	case strings.HasSuffix(f, ".zip"):
+		r.Kind, r.Ref = ZipRequest, strings.TrimSuffix(f, ".zip")
+	default:
+		return nil, fmt.Errorf("Invalid proxy path %s", p)
+	}
+	if r.Kind != ListRequest && r.Ref == "" {
+		return nil, fmt.Errorf("Invalid proxy path %s", p)
+	}
+
+	return r, nil
+}
+
+// ZipURL returns the location of the zip archive of a revision of a package.
+func ZipURL(base, name, rev string) string {
+	return base + "/" + name + "/@v/" + url.PathEscape(rev) + ".zip"
+}
+
+func get(u string) ([]byte, error) {
+	resp, err := http.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unable to fetch %s: %s", u, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,109 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFor(t *testing.T) {
+	defer func() { proxy = nil }()
+
+	if _, ok := For("github.com/example/foo"); ok {
+		t.Error("Expected no proxy before one is loaded")
+	}
+
+	err := loadFromYaml([]byte(`url: https://proxy.example.com/
+exclude:
+- github.com/private/
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u, ok := For("github.com/example/foo"); !ok || u != "https://proxy.example.com" {
+		t.Errorf("Unexpected proxy %q for github.com/example/foo", u)
+	}
+	if _, ok := For("github.com/private/foo"); ok {
+		t.Error("Expected github.com/private/foo to be excluded")
+	}
+	if _, ok := For("github.com/privateer/foo"); !ok {
+		t.Error("Expected github.com/privateer/foo not to be excluded")
+	}
+}
+
+func TestProtocol(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.EscapedPath() {
+		case "/github.com/example/foo/@v/list":
+			w.Write([]byte("v1.0.0\nv1.1.0\n\n"))
+		case "/github.com/example/foo/@v/v1.1.0.info":
+			w.Write([]byte(`{"Version": "v1.1.0", "Revision": "abc123", "Time": "2017-01-02T15:04:05Z"}`))
+		case "/github.com/example/foo/@v/feature%2Fx.info":
+			w.Write([]byte(`{"Version": "feature/x", "Revision": "def456"}`))
+		case "/github.com/example/foo/@latest":
+			w.Write([]byte(`{"Version": "master", "Revision": "fff000"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	vers, err := Versions(ts.URL, "github.com/example/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vers) != 2 || vers[0] != "v1.0.0" || vers[1] != "v1.1.0" {
+		t.Errorf("Unexpected versions %v", vers)
+	}
+
+	i, err := GetInfo(ts.URL, "github.com/example/foo", "v1.1.0")
+	if err != nil || i.Revision != "abc123" || i.Time.Year() != 2017 {
+		t.Errorf("Unexpected info %+v (%v)", i, err)
+	}
+	before := calls
+	if _, err := GetInfo(ts.URL, "github.com/example/foo", "v1.1.0"); err != nil || calls != before {
+		t.Error("Expected the info to be reused")
+	}
+
+	if i, err := GetInfo(ts.URL, "github.com/example/foo", "feature/x"); err != nil || i.Revision != "def456" {
+		t.Errorf("Unexpected info for a branch %+v (%v)", i, err)
+	}
+	if i, err := GetInfo(ts.URL, "github.com/example/foo", ""); err != nil || i.Revision != "fff000" {
+		t.Errorf("Unexpected info for the default branch %+v (%v)", i, err)
+	}
+
+	if _, err := Versions(ts.URL, "github.com/example/missing"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound but got %v", err)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path, name, kind, ref string
+	}{
+		{"/github.com/example/foo/@v/list", "github.com/example/foo", ListRequest, ""},
+		{"/github.com/example/foo/@v/v1.0.0.info", "github.com/example/foo", InfoRequest, "v1.0.0"},
+		{"/github.com/example/foo/@v/feature/x.info", "github.com/example/foo", InfoRequest, "feature/x"},
+		{"/github.com/example/foo/@latest", "github.com/example/foo", LatestRequest, ""},
+		{"/github.com/example/foo/@v/abc123.zip", "github.com/example/foo", ZipRequest, "abc123"},
+	}
+	for _, tt := range tests {
+		r, err := ParsePath(tt.path)
+		if err != nil {
+			t.Errorf("Unable to parse %s: %s", tt.path, err)
+			continue
+		}
+		if r.Name != tt.name || r.Kind != tt.kind || r.Ref != tt.ref {
+			t.Errorf("Unexpected request %+v for %s", r, tt.path)
+		}
+	}
+
+	for _, p := range []string{"/github.com/example/foo", "/@v/list", "/github.com/example/foo/@v/.info", "/github.com/example/foo/@v/v1.0.0.mod"} {
+		if _, err := ParsePath(p); err == nil {
+			t.Errorf("Expected %s to be invalid", p)
+		}
+	}
+}
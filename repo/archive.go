@@ -0,0 +1,361 @@
+package repo
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cp "github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+)
+
+// archiveMarker is the file in the cached copy of an archive recording the
+// URL and checksum it was unpacked from.
+const archiveMarker = ".glide-archive"
+
+// archiveFetcher is a Fetcher for dependencies published as tarballs or zip
+// archives. An archive is downloaded, checked against the checksum of the
+// dependency, and unpacked into the cache. A single top level directory in the
+// archive is removed.
+type archiveFetcher struct{}
+
+// Get downloads an archive unless the cached copy is already from it.
+func (f *archiveFetcher) Get(dep *cfg.Dependency, dest string) error {
+	if archiveCurrent(dep, dest) {
+		msg.Debug("%s is already unpacked from %s", dep.Name, dep.Archive)
+		return nil
+	}
+
+	return downloadArchive(dep, dest)
+}
+
+// Update downloads an archive when the URL or checksum of the dependency
+// differs from the cached copy.
+func (f *archiveFetcher) Update(dep *cfg.Dependency, dest string, force bool) error {
+	if archiveCurrent(dep, dest) {
+		msg.Debug("%s is already unpacked from %s. Skipping update", dep.Name, dep.Archive)
+		return nil
+	}
+	if cp.Offline {
+		return fmt.Errorf("The cached copy of %s is not from %s and can not be downloaded in offline mode", dep.Name, dep.Archive)
+	}
+
+	return downloadArchive(dep, dest)
+}
+
+// Export copies the unpacked archive.
+func (f *archiveFetcher) Export(dep *cfg.Dependency, src, dest string) error {
+	return exportUnpacked(src, dest, archiveMarker)
+}
+
+// exportUnpacked copies an unpacked archive without its marker file.
+func exportUnpacked(src, dest, marker string) error {
+	return filepath.Walk(src, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if rel == marker {
+			return nil
+		}
+		target := filepath.Join(dest, rel)
+
+		if fi.IsDir() {
+			return os.MkdirAll(target, fi.Mode()|0700)
+		}
+
+		return gpath.CopyFile(p, target)
+	})
+}
+
+// archiveChecksum returns the checksum recorded for the archive unpacked at
+// dir.
+func archiveChecksum(dir string) (string, error) {
+	_, sum, err := readArchiveMarker(dir)
+	return sum, err
+}
+
+// archiveCurrent returns true when the copy at dir was unpacked from the
+// archive of a dependency with a matching checksum.
+func archiveCurrent(dep *cfg.Dependency, dir string) bool {
+	u, sum, err := readArchiveMarker(dir)
+	if err != nil || u != dep.Archive {
+		return false
+	}
+
+	return dep.Checksum == "" || normalizeChecksum(dep.Checksum) == sum
+}
+
+func readArchiveMarker(dir string) (string, string, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, archiveMarker))
+	if err != nil {
+		return "", "", err
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		return "", "", fmt.Errorf("Invalid archive information in %s", dir)
+	}
+
+	return lines[0], lines[1], nil
+}
+
+// normalizeChecksum adds the sha256: prefix to a checksum without one.
+func normalizeChecksum(sum string) string {
+	sum = strings.ToLower(strings.TrimSpace(sum))
+	if !strings.Contains(sum, ":") {
+		sum = "sha256:" + sum
+	}
+
+	return sum
+}
+
+// downloadArchive downloads the archive of a dependency, verifies it, and
+// unpacks it to dest replacing anything there.
+func downloadArchive(dep *cfg.Dependency, dest string) error {
+	if c := normalizeChecksum(dep.Checksum); dep.Checksum != "" && !strings.HasPrefix(c, "sha256:") {
+		return fmt.Errorf("Unsupported checksum %s for %s. Only sha256 is supported", dep.Checksum, dep.Name)
+	}
+
+	format, err := archiveFormat(dep.Archive)
+	if err != nil {
+		return err
+	}
+
+	msg.Debug("Downloading %s for %s", dep.Archive, dep.Name)
+	file, sum, err := download(dep.Archive)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if dep.Checksum == "" {
+		msg.Warn("No checksum set for %s. The archive %s has the checksum %s", dep.Name, dep.Archive, sum)
+	} else if normalizeChecksum(dep.Checksum) != sum {
+		return fmt.Errorf("Checksum mismatch for %s: expected %s but %s has %s", dep.Name, dep.Checksum, dep.Archive, sum)
+	}
+
+	return unpack(file, format, dest, archiveMarker, dep.Archive+"\n"+sum+"\n")
+}
+
+// download saves the contents of a URL to a temporary file and returns it
+// with its sha256 checksum. The caller removes the file.
+func download(u string) (*os.File, string, error) {
+	resp, err := http.Get(u)
+	if err != nil {
+		return nil, "", fmt.Errorf("Unable to download %s: %s", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("Unable to download %s: %s", u, resp.Status)
+	}
+
+	file, err := ioutil.TempFile("", "glide-archive")
+	if err != nil {
+		return nil, "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, h), resp.Body); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, "", fmt.Errorf("Unable to download %s: %s", u, err)
+	}
+
+	return file, "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// unpack extracts an archive to dest replacing anything there. The contents
+// are written to the file marker in dest.
+func unpack(file *os.File, format, dest, marker, contents string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempDir(filepath.Dir(dest), ".glide-unpack")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	if _, err := file.Seek(0, 0); err != nil {
+		return err
+	}
+	if format == "zip" {
+		err = unzip(file, tmp)
+	} else {
+		err = untar(file, format, tmp)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to unpack %s: %s", dest, err)
+	}
+
+	// Archives commonly have everything in a single top level directory.
+	root := tmp
+	if files, err := ioutil.ReadDir(tmp); err == nil && len(files) == 1 && files[0].IsDir() {
+		root = filepath.Join(tmp, files[0].Name())
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(root, marker), []byte(contents), 0644); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return err
+	}
+
+	return os.Rename(root, dest)
+}
+
+// archiveFormat detects the format of an archive from the extension in its
+// URL.
+func archiveFormat(loc string) (string, error) {
+	p := strings.ToLower(loc)
+	if u, err := url.Parse(loc); err == nil {
+		p = strings.ToLower(u.Path)
+	}
+
+	switch {
+	case strings.HasSuffix(p, ".zip"):
+		return "zip", nil
+	case strings.HasSuffix(p, ".tar.gz"), strings.HasSuffix(p, ".tgz"):
+		return "tar.gz", nil
+	case strings.HasSuffix(p, ".tar.bz2"), strings.HasSuffix(p, ".tbz2"):
+		return "tar.bz2", nil
+	case strings.HasSuffix(p, ".tar"):
+		return "tar", nil
+	}
+
+	return "", fmt.Errorf("Unable to detect the archive format of %s. Supported formats are .zip, .tar, .tar.gz, .tgz, .tar.bz2, and .tbz2", loc)
+}
+
+// archivePath returns the location in dir for a name from an archive. Names
+// that would be outside of dir are rejected.
+func archivePath(dir, name string) (string, error) {
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if p != dir && !strings.HasPrefix(p, dir+string(os.PathSeparator)) {
+		return "", fmt.Errorf("%s is outside of the archive", name)
+	}
+
+	return p, nil
+}
+
+func untar(r io.Reader, format, dir string) error {
+	switch format {
+	case "tar.gz":
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	case "tar.bz2":
+		r = bzip2.NewReader(bufio.NewReader(r))
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		p, err := archivePath(dir, h.Name)
+		if err != nil {
+			return err
+		}
+
+		switch h.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(p, os.FileMode(h.Mode)|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := writeArchiveFile(p, os.FileMode(h.Mode), tr); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if _, err := archivePath(dir, filepath.Join(filepath.Dir(h.Name), h.Linkname)); err != nil || filepath.IsAbs(h.Linkname) {
+				return fmt.Errorf("%s links outside of the archive", h.Name)
+			}
+			if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(h.Linkname, p); err != nil {
+				return err
+			}
+		default:
+			msg.Debug("Skipping %s in archive", h.Name)
+		}
+	}
+}
+
+func unzip(f *os.File, dir string) error {
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(f, fi.Size())
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		p, err := archivePath(dir, zf.Name)
+		if err != nil {
+			return err
+		}
+		if zf.FileInfo().IsDir() {
+			if err := os.MkdirAll(p, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		err = writeArchiveFile(p, zf.Mode(), rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeArchiveFile(p string, mode os.FileMode, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(p, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
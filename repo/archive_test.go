@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+func testTarball(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, body := range files {
+		h := &tar.Header{Name: name, Mode: 0644, Size: int64(len(body)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestArchiveFetcher(t *testing.T) {
+	b := testTarball(t, map[string]string{
+		"foo-1.0.0/foo.go":     "package foo\n",
+		"foo-1.0.0/bar/bar.go": "package bar\n",
+	})
+	h := sha256.Sum256(b)
+	sum := "sha256:" + hex.EncodeToString(h[:])
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(b)
+	}))
+	defer ts.Close()
+
+	tmp, err := ioutil.TempDir("", "glide-archive-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	dep := &cfg.Dependency{
+		Name:     "example.com/foo",
+		Archive:  ts.URL + "/foo-1.0.0.tar.gz",
+		Checksum: "sha256:0000",
+	}
+	dest := filepath.Join(tmp, "cache")
+	f := fetcherFor(dep)
+
+	err = f.Get(dep, dest)
+	if err == nil || !strings.Contains(err.Error(), "Checksum mismatch") {
+		t.Fatalf("Expected a checksum mismatch but got %v", err)
+	}
+
+	dep.Checksum = strings.TrimPrefix(sum, "sha256:")
+	if err := f.Get(dep, dest); err != nil {
+		t.Fatalf("Unable to get archive: %s", err)
+	}
+	if got, err := archiveChecksum(dest); err != nil || got != sum {
+		t.Errorf("Expected the checksum %s but got %s (%v)", sum, got, err)
+	}
+	if !archiveCurrent(dep, dest) {
+		t.Error("Expected the unpacked archive to be current")
+	}
+
+	vendor := filepath.Join(tmp, "vendor")
+	if err := f.Export(dep, dest, vendor); err != nil {
+		t.Fatalf("Unable to export archive: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(vendor, "bar", "bar.go")); err != nil {
+		t.Errorf("Expected the top level directory to be removed: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(vendor, archiveMarker)); err == nil {
+		t.Error("Expected the archive information not to be exported")
+	}
+}
+
+func TestArchiveUnsafePaths(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "glide-archive-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	b := testTarball(t, map[string]string{"../evil.go": "package evil\n"})
+	if err := untar(bytes.NewReader(b), "tar.gz", tmp); err == nil {
+		t.Error("Expected a file outside of the archive to be rejected")
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "link", Linkname: "../../etc/passwd", Typeflag: tar.TypeSymlink})
+	tw.Close()
+	if err := untar(&buf, "tar", tmp); err == nil {
+		t.Error("Expected a link outside of the archive to be rejected")
+	}
+
+	if _, err := archiveFormat("https://example.com/foo.rar"); err == nil {
+		t.Error("Expected an unknown archive format to be rejected")
+	}
+	if f, _ := archiveFormat("https://example.com/foo.TGZ?token=1"); f != "tar.gz" {
+		t.Errorf("Expected tar.gz but got %s", f)
+	}
+}
@@ -0,0 +1,190 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	cp "github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/msg"
+	v "github.com/Masterminds/vcs"
+)
+
+// CacheEntry describes a repository, archive, or snapshot in the cache.
+type CacheEntry struct {
+	Key     string    `json:"key"`
+	Origin  string    `json:"origin,omitempty"`
+	Size    int64     `json:"size"`
+	LastUse time.Time `json:"lastUse"`
+}
+
+// CacheEntries lists what is in the cache sorted by key.
+func CacheEntries() ([]*CacheEntry, error) {
+	src := filepath.Join(cp.Location(), "src")
+	files, err := ioutil.ReadDir(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*CacheEntry
+	for _, f := range files {
+		// Directories starting with a . are partially unpacked archives.
+		if !f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		p := filepath.Join(src, f.Name())
+		entries = append(entries, &CacheEntry{
+			Key:     f.Name(),
+			Origin:  cacheOrigin(p),
+			Size:    dirSize(p),
+			LastUse: cp.LastUse(f.Name()),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries, nil
+}
+
+// cacheOrigin returns the location the copy in a cache directory came from.
+func cacheOrigin(dir string) string {
+	if u, _, err := readArchiveMarker(dir); err == nil {
+		return u
+	}
+	if base, _, err := readSnapshotMarker(dir); err == nil {
+		return base
+	}
+	if r, err := v.NewRepo("", dir); err == nil {
+		return r.Remote()
+	}
+
+	return ""
+}
+
+// dirSize returns the total size of the files in a directory.
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err == nil && !fi.IsDir() {
+			size += fi.Size()
+		}
+		return nil
+	})
+
+	return size
+}
+
+// GCOptions controls what CacheGC removes.
+type GCOptions struct {
+
+	// KeepDays removes what was not used within the number of days. Zero
+	// keeps everything.
+	KeepDays int
+
+	// MaxSize removes the least recently used entries until the cache is at
+	// most the number of bytes. Zero is no limit.
+	MaxSize int64
+
+	// GitGC runs git gc on the remaining Git repositories.
+	GitGC bool
+
+	// DryRun reports what would be removed without removing it.
+	DryRun bool
+}
+
+// GCResult reports what CacheGC removed and the size of the cache before and
+// after.
+type GCResult struct {
+	Removed []*CacheEntry
+	Before  int64
+	After   int64
+}
+
+// CacheGC removes entries from the cache that were not used recently or do
+// not fit in its maximum size, oldest first, and compacts the remaining Git
+// repositories.
+func CacheGC(o GCOptions) (*GCResult, error) {
+	entries, err := CacheEntries()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUse.Before(entries[j].LastUse)
+	})
+
+	res := &GCResult{}
+	for _, e := range entries {
+		res.Before += e.Size
+	}
+	total := res.Before
+
+	remove := func(e *CacheEntry, why string) error {
+		msg.Info("--> Removing %s (%s)", e.Key, why)
+		if !o.DryRun {
+			if err := cp.Lock(e.Key); err != nil {
+				return err
+			}
+			err := cp.Remove(e.Key)
+			cp.Unlock(e.Key)
+			if err != nil {
+				return err
+			}
+		}
+		res.Removed = append(res.Removed, e)
+		total -= e.Size
+		return nil
+	}
+
+	var keep []*CacheEntry
+	cutoff := time.Now().AddDate(0, 0, -o.KeepDays)
+	for _, e := range entries {
+		if o.KeepDays > 0 && e.LastUse.Before(cutoff) {
+			if err := remove(e, "last used "+e.LastUse.Format("2006-01-02")); err != nil {
+				return res, err
+			}
+			continue
+		}
+		keep = append(keep, e)
+	}
+
+	for o.MaxSize > 0 && total > o.MaxSize && len(keep) > 0 {
+		if err := remove(keep[0], "over the maximum size"); err != nil {
+			return res, err
+		}
+		keep = keep[1:]
+	}
+
+	if o.GitGC && !o.DryRun {
+		for _, e := range keep {
+			p := filepath.Join(cp.Location(), "src", e.Key)
+			if t, err := v.DetectVcsFromFS(p); err != nil || t != v.Git {
+				continue
+			}
+			r, err := v.NewGitRepo("", p)
+			if err != nil {
+				continue
+			}
+			msg.Debug("Running git gc on %s", e.Key)
+			if err := cp.Lock(e.Key); err != nil {
+				msg.Warn("Unable to run git gc on %s: %s", e.Key, err)
+				continue
+			}
+			out, err := r.RunFromDir("git", "gc", "--quiet")
+			cp.Unlock(e.Key)
+			if err != nil {
+				msg.Warn("Unable to run git gc on %s: %s %s", e.Key, err, out)
+				continue
+			}
+			size := dirSize(p)
+			total += size - e.Size
+			e.Size = size
+		}
+	}
+	res.After = total
+
+	return res, nil
+}
@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/glide/cache"
+	gpath "github.com/Masterminds/glide/path"
+)
+
+func TestCacheGC(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "glide-gc-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	home := gpath.Home()
+	gpath.SetHome(tmp)
+	defer gpath.SetHome(home)
+
+	add := func(key string, size int, age time.Duration) {
+		dir := filepath.Join(cache.Location(), "src", key)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		u := "https://example.com/" + key + ".tar.gz"
+		if err := ioutil.WriteFile(filepath.Join(dir, archiveMarker), []byte(u+"\nsha256:00\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "data"), []byte(strings.Repeat("x", size)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := cache.SaveRepoData(key, cache.RepoInfo{
+			LastUse: time.Now().Add(-age).UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	day := 24 * time.Hour
+	add("old", 1000, 90*day)
+	add("older", 1000, 120*day)
+	add("recent", 3000, 10*day)
+	add("new", 2000, time.Hour)
+
+	entries, err := CacheEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 || entries[0].Key != "new" || entries[3].Key != "recent" {
+		t.Fatalf("Unexpected cache entries %v", entries)
+	}
+	if entries[0].Origin != "https://example.com/new.tar.gz" {
+		t.Errorf("Expected the origin of the archive but got %q", entries[0].Origin)
+	}
+	if entries[0].Size < 2000 {
+		t.Errorf("Expected a size of at least 2000 but got %d", entries[0].Size)
+	}
+
+	res, err := CacheGC(GCOptions{KeepDays: 30, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Removed) != 2 || res.Before-res.After < 2000 {
+		t.Errorf("Expected two entries to be removed in the dry run but got %v", res.Removed)
+	}
+	if entries, _ := CacheEntries(); len(entries) != 4 {
+		t.Errorf("Expected a dry run to remove nothing but %d entries remain", len(entries))
+	}
+
+	res, err = CacheGC(GCOptions{KeepDays: 30, MaxSize: 4000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var removed []string
+	for _, e := range res.Removed {
+		removed = append(removed, e.Key)
+	}
+	if strings.Join(removed, ",") != "older,old,recent" {
+		t.Errorf("Expected older, old and recent to be removed but got %v", removed)
+	}
+	entries, err = CacheEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Key != "new" {
+		t.Errorf("Expected only new to remain but got %v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(cache.Location(), "info", "old.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected the data of a removed entry to be removed")
+	}
+	if res.After != entries[0].Size {
+		t.Errorf("Expected the cache to be %d bytes but got %d", entries[0].Size, res.After)
+	}
+}
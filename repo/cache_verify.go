@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cp "github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	v "github.com/Masterminds/vcs"
+)
+
+// CacheCheck is the result of verifying a repository in the cache.
+type CacheCheck struct {
+	Key     string `json:"key"`
+	Origin  string `json:"origin,omitempty"`
+	Problem string `json:"problem,omitempty"`
+
+	// Repaired is true when the repository was cloned again.
+	Repaired bool `json:"repaired,omitempty"`
+}
+
+// VerifyCache checks each repository in the cache using the consistency check
+// of its VCS and compares its remote to the one recorded when it was cloned.
+// When repair is true corrupt repositories are cloned again.
+func VerifyCache(repair bool) ([]*CacheCheck, error) {
+	entries, err := CacheEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]*CacheCheck, 0, len(entries))
+	for _, e := range entries {
+		msg.Debug("Verifying %s", e.Key)
+		if err := cp.Lock(e.Key); err != nil {
+			checks = append(checks, &CacheCheck{Key: e.Key, Problem: err.Error()})
+			continue
+		}
+		c := verifyCacheEntry(e.Key)
+		if c.Problem != "" && repair {
+			if err := repairCacheEntry(c); err != nil {
+				c.Problem += fmt.Sprintf(". Unable to repair: %s", err)
+			} else {
+				c.Repaired = true
+			}
+		}
+		cp.Unlock(e.Key)
+		checks = append(checks, c)
+	}
+
+	return checks, nil
+}
+
+// verifyCacheEntry checks a single repository in the cache.
+func verifyCacheEntry(key string) *CacheCheck {
+	dir := filepath.Join(cp.Location(), "src", key)
+	c := &CacheCheck{Key: key}
+	info, _ := cp.RepoData(key)
+	c.Origin = info.Remote
+
+	// Archives and snapshots are unpacked elsewhere and moved into place so
+	// they are complete when their marker is present.
+	if u, _, err := readArchiveMarker(dir); err == nil {
+		c.Origin = u
+		return c
+	}
+	if base, _, err := readSnapshotMarker(dir); err == nil {
+		c.Origin = base
+		return c
+	}
+
+	if _, err := v.DetectVcsFromFS(dir); err != nil {
+		c.Problem = "Cache directory missing VCS information"
+		return c
+	}
+	repo, err := v.NewRepo("", dir)
+	if err != nil {
+		c.Problem = fmt.Sprintf("Unable to read the repository: %s", err)
+		return c
+	}
+	if c.Origin == "" {
+		c.Origin = repo.Remote()
+	}
+
+	if out, err := checkRepo(repo); err != nil {
+		c.Problem = fmt.Sprintf("Consistency check failed: %s", strings.TrimSpace(string(out)))
+		if len(out) == 0 {
+			c.Problem = fmt.Sprintf("Consistency check failed: %s", err)
+		}
+		return c
+	}
+
+	if info.Remote != "" && repo.Remote() != info.Remote {
+		c.Problem = fmt.Sprintf("The remote %s does not match %s it was cloned from", repo.Remote(), info.Remote)
+	} else if k, err := cp.Key(repo.Remote()); err == nil && k != key {
+		c.Problem = fmt.Sprintf("The remote %s does not belong in the cache location %s", repo.Remote(), key)
+	}
+
+	return c
+}
+
+// checkRepo runs the consistency check of the VCS of a repository.
+func checkRepo(repo v.Repo) ([]byte, error) {
+	switch repo.Vcs() {
+	case v.Git:
+		// A clone that was interrupted can have no commits at all.
+		if out, err := repo.RunFromDir("git", "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+			return append(out, []byte("HEAD does not point to a commit")...), err
+		}
+		return repo.RunFromDir("git", "fsck", "--connectivity-only", "--no-progress", "--no-dangling")
+	case v.Hg:
+		return repo.RunFromDir("hg", "verify", "--quiet")
+	case v.Bzr:
+		return repo.RunFromDir("bzr", "check")
+	case v.Svn:
+		return repo.RunFromDir("svn", "info")
+	}
+
+	return nil, nil
+}
+
+// repairCacheEntry removes a corrupt repository and clones it again from the
+// remote it was cloned from.
+func repairCacheEntry(c *CacheCheck) error {
+	if c.Origin == "" {
+		return fmt.Errorf("the remote it was cloned from is unknown")
+	}
+	if cp.Offline {
+		return fmt.Errorf("repositories can not be cloned in offline mode")
+	}
+	if k, err := cp.Key(c.Origin); err != nil || k != c.Key {
+		return fmt.Errorf("%s does not belong in the cache location %s", c.Origin, c.Key)
+	}
+
+	msg.Info("--> Cloning %s into the cache again", c.Origin)
+	dir := filepath.Join(cp.Location(), "src", c.Key)
+	dep := &cfg.Dependency{Name: c.Key, Repository: c.Origin}
+	if t, err := v.DetectVcsFromFS(dir); err == nil {
+		dep.VcsType = string(t)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+
+	return VcsGet(dep)
+}
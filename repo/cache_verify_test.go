@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	gpath "github.com/Masterminds/glide/path"
+)
+
+func TestVerifyCache(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	tmp, err := ioutil.TempDir("", "glide-verify-cache-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	home := gpath.Home()
+	gpath.SetHome(filepath.Join(tmp, "home"))
+	defer gpath.SetHome(home)
+
+	git := func(dir string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %s %s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	origin := filepath.Join(tmp, "origin")
+	if err := os.MkdirAll(origin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(origin, "foo.go"), []byte("package foo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(origin, "init", "-q")
+	git(origin, "add", "foo.go")
+	git(origin, "-c", "user.name=glide", "-c", "user.email=glide@example.com", "commit", "-q", "-m", "Initial")
+
+	remote := "file://" + filepath.ToSlash(origin)
+	dep := &cfg.Dependency{Name: "example.com/foo", Repository: remote, VcsType: "git"}
+	if err := VcsGet(dep); err != nil {
+		t.Fatal(err)
+	}
+	key, err := cache.Key(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := cache.RepoData(key); err != nil || d.Remote != remote {
+		t.Errorf("Expected the remote %s to be recorded but got %v %v", remote, d, err)
+	}
+
+	// A directory without any VCS information can not be repaired.
+	if err := os.MkdirAll(filepath.Join(cache.Location(), "src", "broken"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	checks, err := VerifyCache(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems := map[string]string{}
+	for _, c := range checks {
+		problems[c.Key] = c.Problem
+	}
+	if len(checks) != 2 || problems[key] != "" || problems["broken"] == "" {
+		t.Fatalf("Unexpected checks %v", problems)
+	}
+
+	// Simulate a clone that was interrupted before any commits were fetched.
+	dir := filepath.Join(cache.Location(), "src", key)
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git(dir, "init", "-q")
+	git(dir, "remote", "add", "origin", remote)
+
+	checks, err = VerifyCache(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range checks {
+		switch c.Key {
+		case key:
+			if !c.Repaired || c.Problem == "" {
+				t.Errorf("Expected %s to be repaired but got %+v", key, c)
+			}
+		case "broken":
+			if c.Repaired || !strings.Contains(c.Problem, "Unable to repair") {
+				t.Errorf("Expected broken to not be repaired but got %+v", c)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "foo.go")); err != nil {
+		t.Errorf("Expected the repaired repository to be cloned again: %s", err)
+	}
+
+	if c := verifyCacheEntry(key); c.Problem != "" {
+		t.Errorf("Expected no problem after the repair but got %s", c.Problem)
+	}
+}
@@ -0,0 +1,210 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+
+	cp "github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/proxy"
+	v "github.com/Masterminds/vcs"
+)
+
+// Fetcher retrieves the source of dependencies into the cache and exports it
+// to the vendor/ directory.
+type Fetcher interface {
+
+	// Get adds a dependency to the cache at dest or, when it is already
+	// there, updates it.
+	Get(dep *cfg.Dependency, dest string) error
+
+	// Update brings the existing copy of a dependency at dest up to date.
+	// When force is true a copy from a different location may be replaced.
+	Update(dep *cfg.Dependency, dest string, force bool) error
+
+	// Export copies the source of a dependency from the cache at src to dest.
+	Export(dep *cfg.Dependency, src, dest string) error
+}
+
+// fetcherFor returns the Fetcher for a dependency. Dependencies with an archive
+// are downloaded over HTTP. Those without a repository set in the glide.yaml
+// file are fetched from a proxy when one is configured. All others use their
+// VCS.
+func fetcherFor(dep *cfg.Dependency) Fetcher {
+	if dep.Archive != "" {
+		return &archiveFetcher{}
+	}
+	if dep.Repository == "" && dep.VcsType == "" {
+		if base, ok := proxy.For(dep.Name); ok {
+			return &proxyFetcher{base: base, vcs: &vcsFetcher{}}
+		}
+	}
+
+	return &vcsFetcher{}
+}
+
+// vcsFetcher is a Fetcher for Git, Mercurial, Bazaar, and Subversion
+// repositories.
+type vcsFetcher struct{}
+
+// Get clones a repository into the cache or updates it.
+func (f *vcsFetcher) Get(dep *cfg.Dependency, d string) error {
+	key, err := cp.Key(dep.Remote())
+	if err != nil {
+		return err
+	}
+	repo, err := dep.GetRepo(d)
+	if err != nil {
+		return err
+	}
+	// If the directory does not exist this is a first cache.
+	if _, err = os.Stat(d); os.IsNotExist(err) {
+		msg.Debug("Adding %s to the cache for the first time", dep.Name)
+		if useShallow(dep) && repo.Vcs() == v.Git {
+			err = shallowClone(repo)
+		} else {
+			err = repo.Get()
+		}
+		if err != nil {
+			return err
+		}
+
+		// The remote is saved so the cache can be verified and repaired.
+		msg.Debug("Saving the default branch and remote for %s", repo.Remote())
+		c := cp.RepoInfo{DefaultBranch: findCurrentBranch(repo), Remote: repo.Remote()}
+		err = cp.SaveRepoData(key, c)
+		if err == cp.ErrCacheDisabled {
+			msg.Debug("Unable to cache default branch because caching is disabled")
+		} else if err != nil {
+			msg.Debug("Error saving %s to cache. Error: %s", repo.Remote(), err)
+		}
+	} else {
+		msg.Debug("Updating %s in the cache", dep.Name)
+		if isShallow(repo) {
+			err = shallowUpdate(repo)
+		} else {
+			err = repo.Update()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Update updates a repository in the cache.
+func (f *vcsFetcher) Update(dep *cfg.Dependency, dest string, force bool) error {
+
+	// When the directory is not empty and has no VCS directory it's
+	// a vendored files situation.
+	empty, err := gpath.IsDirectoryEmpty(dest)
+	if err != nil {
+		return err
+	}
+	_, err = v.DetectVcsFromFS(dest)
+	if empty == true && err == v.ErrCannotDetectVCS {
+		msg.Warn("Cached version of %s is an empty directory. Fetching a new copy of the dependency", dep.Name)
+		msg.Debug("Removing empty directory %s", dest)
+		err := os.RemoveAll(dest)
+		if err != nil {
+			return err
+		}
+		if cp.Offline {
+			return errOffline(dep)
+		}
+		if err = f.Get(dep, dest); err != nil {
+			msg.Warn("Unable to checkout %s\n", dep.Name)
+			return err
+		}
+	} else {
+		repo, err := dep.GetRepo(dest)
+
+		// Tried to checkout a repo to a path that does not work. Either the
+		// type or endpoint has changed. Force is being passed in so the old
+		// location can be removed and replaced with the new one.
+		// Warning, any changes in the old location will be deleted.
+		// TODO: Put dirty checking in on the existing local checkout.
+		if (err == v.ErrWrongVCS || err == v.ErrWrongRemote) && force == true {
+			newRemote := dep.Remote()
+			if cp.Offline {
+				return fmt.Errorf("Unable to replace %s with contents from %s in offline mode", dep.Name, newRemote)
+			}
+
+			msg.Warn("Replacing %s with contents from %s\n", dep.Name, newRemote)
+			rerr := os.RemoveAll(dest)
+			if rerr != nil {
+				return rerr
+			}
+			if err = f.Get(dep, dest); err != nil {
+				msg.Warn("Unable to checkout %s\n", dep.Name)
+				return err
+			}
+
+			repo, err = dep.GetRepo(dest)
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		} else if repo.IsDirty() {
+			return fmt.Errorf("%s contains uncommitted changes. Skipping update", dep.Name)
+		}
+
+		ver := dep.Reference
+		if ver == "" {
+			ver = defaultBranch(repo)
+		}
+		// Check if the current version is a tag or commit id. If it is
+		// and that version is already checked out we can skip updating
+		// which is faster than going out to the Internet to perform
+		// an update.
+		if ver != "" {
+			version, err := repo.Version()
+			if err != nil {
+				return err
+			}
+			ib, err := isBranch(ver, repo)
+			if err != nil {
+				return err
+			}
+
+			// If the current version equals the ref and it's not a
+			// branch it's a tag or commit id so we can skip
+			// performing an update.
+			if version == ver && !ib {
+				msg.Debug("%s is already set to version %s. Skipping update", dep.Name, dep.Reference)
+				return nil
+			}
+		}
+
+		if cp.Offline {
+			msg.Debug("Offline mode. Using the cached copy of %s without fetching updates", dep.Name)
+			return nil
+		}
+
+		if isShallow(repo) {
+			err = shallowUpdate(repo)
+		} else {
+			err = repo.Update()
+		}
+		if err != nil {
+			msg.Warn("Download failed.\n")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Export exports the files of a repository without the VCS metadata.
+func (f *vcsFetcher) Export(dep *cfg.Dependency, src, dest string) error {
+	repo, err := dep.GetRepo(src)
+	if err != nil {
+		return err
+	}
+
+	return repo.ExportDir(dest)
+}
@@ -0,0 +1,107 @@
+package repo
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+)
+
+// TreeHash generates a deterministic sha256 hash of the contents of a
+// directory tree, such as a dependency exported to the vendor/ directory.
+//
+// The hash covers the relative path, type, and contents of every file. File
+// modes are not included as they are not consistent across operating systems.
+// VCS metadata directories are skipped as they are not exported. Nested
+// vendor/ and Godeps/_workspace directories are skipped as well so that
+// stripping them (see --strip-vendor) does not change the hash.
+func TreeHash(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if fi.IsDir() {
+			if skipTreeHashDir(rel, fi.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
+			ln, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "l %s %s\n", rel, filepath.ToSlash(ln))
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		fh := sha256.New()
+		if _, err := io.Copy(fh, f); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(h, "f %s %x\n", rel, fh.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// setTreeHash hashes the exported copy of a dependency. When the dependency
+// already carries a hash, such as one read from glide.lock, the exported
+// contents must match it. Otherwise the new hash is recorded on the dependency
+// so it can be written to the lock file. Local dependencies change as they
+// are worked on so their hash is always recorded rather than checked.
+func setTreeHash(dep *cfg.Dependency, dir string) error {
+	h, err := TreeHash(dir)
+	if err != nil {
+		return err
+	}
+
+	if dep.Hash == "" || dep.Path != "" {
+		dep.Hash = h
+		return nil
+	}
+
+	if dep.Hash != h {
+		return fmt.Errorf("Hash mismatch for %s at %s: the lock file has %s but the exported code has %s", dep.Name, dep.Pin, dep.Hash, h)
+	}
+
+	msg.Debug("Verified hash of %s", dep.Name)
+	return nil
+}
+
+func skipTreeHashDir(rel, name string) bool {
+	switch name {
+	case ".git", ".hg", ".bzr", ".svn", "vendor":
+		return true
+	}
+
+	return rel == "Godeps/_workspace" || strings.HasSuffix(rel, "/Godeps/_workspace")
+}
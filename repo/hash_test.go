@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+func TestTreeHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-treehash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(p, c string) {
+		fp := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fp, []byte(c), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("foo.go", "package foo")
+	write("bar/bar.go", "package bar")
+
+	h1, err := TreeHash(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// VCS metadata and nested vendor directories are not part of the hash.
+	write(".git/HEAD", "ref: refs/heads/master")
+	write("vendor/github.com/example/baz/baz.go", "package baz")
+	write("Godeps/_workspace/src/example.com/qux/qux.go", "package qux")
+
+	h2, err := TreeHash(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h1 != h2 {
+		t.Errorf("Expected VCS and vendor directories to be ignored, got %s and %s", h1, h2)
+	}
+
+	write("bar/bar.go", "package bar // changed")
+	h3, err := TreeHash(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h1 == h3 {
+		t.Error("Expected a changed file to change the hash")
+	}
+
+	dep := &cfg.Dependency{Name: "example.com/foo"}
+	if err := setTreeHash(dep, dir); err != nil {
+		t.Errorf("Unexpected error setting a hash: %s", err)
+	}
+	if dep.Hash != h3 {
+		t.Errorf("Expected hash %s to be recorded but got %s", h3, dep.Hash)
+	}
+
+	dep.Hash = h1
+	if err := setTreeHash(dep, dir); err == nil {
+		t.Error("Expected an error when the hash does not match")
+	}
+}
@@ -7,9 +7,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
-	"sync"
 	"syscall"
-	"time"
 
 	"github.com/Masterminds/glide/cache"
 	"github.com/Masterminds/glide/cfg"
@@ -18,9 +16,6 @@ import (
 	"github.com/Masterminds/glide/msg"
 	gpath "github.com/Masterminds/glide/path"
 	"github.com/Masterminds/glide/util"
-	"github.com/Masterminds/semver"
-	"github.com/Masterminds/vcs"
-	"github.com/codegangsta/cli"
 )
 
 // Installer provides facilities for installing the repos in a config file.
@@ -45,6 +40,14 @@ type Installer struct {
 
 	// Updated tracks the packages that have been remotely fetched.
 	Updated *UpdateTracker
+
+	// Locked are the dependencies kept at their versions in the lock file
+	// while updating. See KeepLocked.
+	Locked map[string]*cfg.Lock
+
+	// PreferNestedLocks uses the versions in the glide.lock files of
+	// dependencies as hints when choosing the versions of their dependencies.
+	PreferNestedLocks bool
 }
 
 // NewInstaller returns an Installer instance ready to use. This is the constructor.
@@ -109,6 +112,7 @@ func (i *Installer) Install(lock *cfg.Lockfile, conf *cfg.Config) (*cfg.Config,
 // This is used when initializing an empty vendor directory, or when updating a
 // vendor directory based on changed config.
 func (i *Installer) Checkout(conf *cfg.Config) error {
+	i.applyLocked(conf)
 
 	msg.Info("Downloading dependencies. Please wait...")
 
@@ -131,8 +135,74 @@ func (i *Installer) Checkout(conf *cfg.Config) error {
 //
 // In other words, all versions in the Lockfile will be empty.
 func (i *Installer) Update(conf *cfg.Config) error {
-	base := "."
+	root := append(rootRequirements(conf, i.ResolveTest), i.lockedRequirements()...)
+	imports := conf.Imports.Clone()
+	devImports := conf.DevImports.Clone()
+
+	// The imports of a package can change between versions. Packages whose
+	// version was changed by the solver are resolved again at the new one
+	// until the versions stop changing.
+	solved := map[string]string{}
+	for pass := 1; ; pass++ {
+		if pass > 1 {
+			msg.Debug("Resolving imports again for the versions chosen")
+			conf.Imports = imports.Clone()
+			conf.DevImports = devImports.Clone()
+
+			// The dependencies in the glide.yaml file are checked out before
+			// resolving, as they were before the first pass.
+			for _, d := range append(append(cfg.Dependencies{}, conf.Imports...), conf.DevImports...) {
+				if ver := solved[d.Name]; ver != "" && ver != d.Reference {
+					d.Reference = ver
+					d.Pin = ""
+					d.Hash = ""
+				}
+			}
+			if err := SetReference(conf, i.ResolveTest); err != nil {
+				return err
+			}
+		}
+		reqs := i.resolve(conf, solved)
+
+		changed, err := i.solve(conf, root, reqs, solved)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			break
+		}
+		if pass == maxResolvePasses {
+			msg.Warn("The versions chosen were still changing after resolving the imports %d times. Some imports of them may be missing", pass)
+			break
+		}
+	}
+
+	msg.Info("Downloading dependencies. Please wait...")
+
+	err := ConcurrentUpdate(conf.Imports, i, conf)
+	if err != nil {
+		return err
+	}
+
+	if i.ResolveTest {
+		err = ConcurrentUpdate(conf.DevImports, i, conf)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
+// maxResolvePasses limits how many times Update resolves the imports for the
+// versions chosen by the solver.
+const maxResolvePasses = 5
+
+// resolve walks the imports of the project with the packages at the versions
+// already solved, adding the dependencies to the config. It returns the
+// requirements imported from the configuration of each package.
+func (i *Installer) resolve(conf *cfg.Config, solved map[string]string) map[string][]Requirement {
+	base := "."
 	ic := newImportCache()
 
 	m := &MissingPackageHandler{
@@ -144,10 +214,14 @@ func (i *Installer) Update(conf *cfg.Config) error {
 	}
 
 	v := &VersionHandler{
-		Use:       ic,
-		Imported:  make(map[string]bool),
-		Conflicts: make(map[string]bool),
-		Config:    conf,
+		Use:          ic,
+		Imported:     make(map[string]bool),
+		Conflicts:    make(map[string]bool),
+		Requirements: make(map[string][]Requirement),
+		Config:       conf,
+		Locked:       i.Locked,
+		NestedLocks:  i.PreferNestedLocks,
+		Solved:       solved,
 	}
 
 	// Update imports
@@ -227,21 +301,88 @@ func (i *Installer) Update(conf *cfg.Config) error {
 		}
 	}
 
-	msg.Info("Downloading dependencies. Please wait...")
+	return v.Requirements
+}
+
+// solve chooses the versions of the dependencies that satisfy all of the
+// requirements on them and sets the references to them. The versions are
+// recorded in solved. It returns true when the version of a package differs
+// from the one its imports were resolved at.
+func (i *Installer) solve(conf *cfg.Config, root []Requirement, observed map[string][]Requirement, solved map[string]string) (bool, error) {
+	deps := append(cfg.Dependencies{}, conf.Imports...)
+	if i.ResolveTest {
+		deps = append(deps, conf.DevImports...)
+	}
+	var names []string
+	for _, d := range deps {
+		if conf.HasIgnore(d.Name) {
+			continue
+		}
+
+		// Local and archive dependencies have no versions to choose from.
+		// Their requirements still apply to the others.
+		if d.Path != "" || d.Archive != "" {
+			for _, r := range observed[d.Name] {
+				r.From = d.Name
+				root = append(root, r)
+			}
+			continue
+		}
+		names = append(names, d.Name)
+	}
 
-	err = ConcurrentUpdate(conf.Imports, i, conf)
+	msg.Info("Solving version constraints")
+	src := newVcsSource(conf, observed, i.PreferNestedLocks)
+	sol, err := Solve(src, root, names)
 	if err != nil {
-		return err
+		return false, i.lockedConflict(err)
 	}
 
-	if i.ResolveTest {
-		err = ConcurrentUpdate(conf.DevImports, i, conf)
-		if err != nil {
-			return err
+	changed := false
+	for _, d := range deps {
+		if l, ok := i.Locked[d.Name]; ok {
+			lockDependency(d, l)
+			continue
+		}
+		ver := sol[d.Name]
+		if ver != "" {
+			solved[d.Name] = ver
+		}
+		if ver != "" && ver != d.Reference {
+			msg.Debug("--> Using %s %s", d.Name, ver)
+			if d.Pin != "" && !src.resolvedAt(d.Name, ver, d.Pin) {
+				changed = true
+			}
+			d.Reference = ver
+			// Clear the pin and hash so the version is set again.
+			d.Pin = ""
+			d.Hash = ""
 		}
 	}
 
-	return nil
+	return changed, nil
+}
+
+// rootRequirements returns the versions asked for in the glide.yaml file,
+// including the overrides.
+func rootRequirements(conf *cfg.Config, addTest bool) []Requirement {
+	deps := append(cfg.Dependencies{}, conf.Imports...)
+	if addTest {
+		deps = append(deps, conf.DevImports...)
+	}
+	var reqs []Requirement
+	for _, d := range deps {
+		if d.Reference != "" && d.Path == "" && d.Archive == "" && !conf.Overrides.Has(d.Name) {
+			reqs = append(reqs, Requirement{Name: d.Name, Reference: d.Reference})
+		}
+	}
+	for _, o := range conf.Overrides {
+		if o.Reference != "" {
+			reqs = append(reqs, Requirement{Name: o.Name, Reference: o.Reference, Override: true})
+		}
+	}
+
+	return reqs
 }
 
 // Export from the cache to the vendor directory
@@ -253,7 +394,7 @@ func (i *Installer) Export(conf *cfg.Config) error {
 	defer func() {
 		err = os.RemoveAll(tempDir)
 		if err != nil {
-			msg.Err(err.Error())
+			msg.Err("%s", err)
 		}
 	}()
 
@@ -261,96 +402,61 @@ func (i *Installer) Export(conf *cfg.Config) error {
 	err = os.MkdirAll(vp, 0755)
 
 	msg.Info("Exporting resolved dependencies...")
-	done := make(chan struct{}, concurrentWorkers)
-	in := make(chan *cfg.Dependency, concurrentWorkers)
-	var wg sync.WaitGroup
-	var lock sync.Mutex
-	var returnErr error
-
-	for ii := 0; ii < concurrentWorkers; ii++ {
-		go func(ch <-chan *cfg.Dependency) {
-			for {
-				select {
-				case dep := <-ch:
-					loc := dep.Remote()
-					key, err := cache.Key(loc)
-					if err != nil {
-						msg.Die(err.Error())
-					}
-					cache.Lock(key)
-
-					cdir := filepath.Join(cache.Location(), "src", key)
-					repo, err := dep.GetRepo(cdir)
-					if err != nil {
-						msg.Die(err.Error())
-					}
-					msg.Info("--> Exporting %s", dep.Name)
-					if err := repo.ExportDir(filepath.Join(vp, filepath.ToSlash(dep.Name))); err != nil {
-						msg.Err("Export failed for %s: %s\n", dep.Name, err)
-						// Capture the error while making sure the concurrent
-						// operations don't step on each other.
-						lock.Lock()
-						if returnErr == nil {
-							returnErr = err
-						} else {
-							returnErr = cli.NewMultiError(returnErr, err)
-						}
-						lock.Unlock()
-					}
-					cache.Unlock(key)
-					wg.Done()
-				case <-done:
-					return
-				}
-			}
-		}(in)
-	}
-
+	var deps []*cfg.Dependency
 	for _, dep := range conf.Imports {
 		if !conf.HasIgnore(dep.Name) {
-			err = os.MkdirAll(filepath.Join(vp, filepath.ToSlash(dep.Name)), 0755)
-			if err != nil {
-				lock.Lock()
-				if returnErr == nil {
-					returnErr = err
-				} else {
-					returnErr = cli.NewMultiError(returnErr, err)
-				}
-				lock.Unlock()
-			}
-			wg.Add(1)
-			in <- dep
+			deps = append(deps, dep)
 		}
 	}
-
 	if i.ResolveTest {
 		for _, dep := range conf.DevImports {
 			if !conf.HasIgnore(dep.Name) {
-				err = os.MkdirAll(filepath.Join(vp, filepath.ToSlash(dep.Name)), 0755)
-				if err != nil {
-					lock.Lock()
-					if returnErr == nil {
-						returnErr = err
-					} else {
-						returnErr = cli.NewMultiError(returnErr, err)
-					}
-					lock.Unlock()
-				}
-				wg.Add(1)
-				in <- dep
+				deps = append(deps, dep)
 			}
 		}
 	}
 
-	wg.Wait()
+	err = concurrently(deps, func(dep *cfg.Dependency) error {
+		dest := filepath.Join(vp, filepath.ToSlash(dep.Name))
+		if err := os.MkdirAll(dest, 0755); err != nil {
+			return err
+		}
 
-	// Close goroutines setting the version
-	for ii := 0; ii < concurrentWorkers; ii++ {
-		done <- struct{}{}
-	}
+		key, err := cache.Key(dep.Remote())
+		if err != nil {
+			msg.With(msg.Fields{"package": dep.Name, "action": "exporting"}).Err("Export failed for %s: %s\n", dep.Name, err)
+			return err
+		}
+		if err := cache.Lock(key); err != nil {
+			msg.With(msg.Fields{"package": dep.Name, "action": "exporting"}).Err("Export failed for %s: %s\n", dep.Name, err)
+			return err
+		}
+		defer cache.Unlock(key)
+
+		msg.With(msg.Fields{"package": dep.Name, "version": dep.Pin, "action": "exporting"}).Info("--> Exporting %s", dep.Name)
+		if dep.Path != "" {
+			err = copyLocal(dep, dest)
+		} else {
+			cdir := filepath.Join(cache.Location(), "src", key)
+			err = fetcherFor(dep).Export(dep, cdir, dest)
+		}
+		if err == nil {
+			err = setTreeHash(dep, dest)
+		}
+		if err != nil {
+			msg.With(msg.Fields{"package": dep.Name, "action": "exporting"}).Err("Export failed for %s: %s\n", dep.Name, err)
+			return err
+		}
 
-	if returnErr != nil {
-		return returnErr
+		if dep.Path == "" {
+			if terr := cache.Touch(key); terr != nil && terr != cache.ErrCacheDisabled {
+				msg.Debug("Unable to record the use of %s in the cache: %s", dep.Name, terr)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	msg.Info("Replacing existing vendor dependencies")
@@ -508,59 +614,33 @@ func LazyConcurrentUpdate(deps []*cfg.Dependency, i *Installer, c *cfg.Config) e
 
 // ConcurrentUpdate takes a list of dependencies and updates in parallel.
 func ConcurrentUpdate(deps []*cfg.Dependency, i *Installer, c *cfg.Config) error {
-	done := make(chan struct{}, concurrentWorkers)
-	in := make(chan *cfg.Dependency, concurrentWorkers)
-	var wg sync.WaitGroup
-	var lock sync.Mutex
-	var returnErr error
-
-	for ii := 0; ii < concurrentWorkers; ii++ {
-		go func(ch <-chan *cfg.Dependency) {
-			for {
-				select {
-				case dep := <-ch:
-					loc := dep.Remote()
-					key, err := cache.Key(loc)
-					if err != nil {
-						msg.Die(err.Error())
-					}
-					cache.Lock(key)
-					if err := VcsUpdate(dep, i.Force, i.Updated); err != nil {
-						msg.Err("Update failed for %s: %s\n", dep.Name, err)
-						// Capture the error while making sure the concurrent
-						// operations don't step on each other.
-						lock.Lock()
-						if returnErr == nil {
-							returnErr = err
-						} else {
-							returnErr = cli.NewMultiError(returnErr, err)
-						}
-						lock.Unlock()
-					}
-					cache.Unlock(key)
-					wg.Done()
-				case <-done:
-					return
-				}
-			}
-		}(in)
-	}
-
+	var todo []*cfg.Dependency
 	for _, dep := range deps {
 		if !c.HasIgnore(dep.Name) {
-			wg.Add(1)
-			in <- dep
+			todo = append(todo, dep)
 		}
 	}
 
-	wg.Wait()
+	err := concurrently(todo, func(dep *cfg.Dependency) error {
+		key, err := cache.Key(dep.Remote())
+		if err == nil {
+			err = cache.Lock(key)
+		}
+		if err == nil {
+			err = VcsUpdate(dep, i.Force, i.Updated)
+			cache.Unlock(key)
+		}
+		if err != nil {
+			msg.With(msg.Fields{"package": dep.Name, "action": "updating"}).Err("Update failed for %s: %s\n", dep.Name, err)
+		}
+		return err
+	})
 
-	// Close goroutines setting the version
-	for ii := 0; ii < concurrentWorkers; ii++ {
-		done <- struct{}{}
+	// The caller commonly exits on an error so the retries are shown now.
+	if err != nil {
+		LogRetrySummary()
 	}
-
-	return returnErr
+	return err
 }
 
 // allPackages gets a list of all packages required to satisfy the given deps.
@@ -630,13 +710,13 @@ func (m *MissingPackageHandler) InVendor(pkg string, addTest bool) error {
 
 // PkgPath resolves the location on the filesystem where the package should be.
 // This handles making sure to use the cache location.
-func (m *MissingPackageHandler) PkgPath(pkg string) string {
+func (m *MissingPackageHandler) PkgPath(pkg string) (string, error) {
 	root, sub := util.NormalizeName(pkg)
 
 	// For the parent applications source skip the cache.
 	if root == m.Config.Name {
 		pth := gpath.Basepath()
-		return filepath.Join(pth, filepath.FromSlash(sub))
+		return filepath.Join(pth, filepath.FromSlash(sub)), nil
 	}
 
 	d := m.Config.Imports.Get(root)
@@ -649,15 +729,20 @@ func (m *MissingPackageHandler) PkgPath(pkg string) string {
 
 		if d == nil {
 			d = &cfg.Dependency{Name: root}
+			applyOverride(m.Config, d, "")
 		}
 	}
 
+	if d.Path != "" {
+		return filepath.Join(localDir(d), filepath.FromSlash(sub)), nil
+	}
+
 	key, err := cache.Key(d.Remote())
 	if err != nil {
-		msg.Die("Error generating cache key for %s", d.Name)
+		return "", fmt.Errorf("Error generating cache key for %s: %s", d.Name, err)
 	}
 
-	return filepath.Join(cache.Location(), "src", key, filepath.FromSlash(sub))
+	return filepath.Join(cache.Location(), "src", key, filepath.FromSlash(sub)), nil
 }
 
 func (m *MissingPackageHandler) fetchToCache(pkg string, addTest bool) error {
@@ -679,6 +764,7 @@ func (m *MissingPackageHandler) fetchToCache(pkg string, addTest bool) error {
 		if d == nil {
 			d = &cfg.Dependency{Name: root}
 		}
+		applyOverride(m.Config, d, "")
 
 		if addTest {
 			m.Config.DevImports = append(m.Config.DevImports, d)
@@ -687,6 +773,15 @@ func (m *MissingPackageHandler) fetchToCache(pkg string, addTest bool) error {
 		}
 	}
 
+	key, err := cache.Key(d.Remote())
+	if err != nil {
+		return err
+	}
+	if err := cache.Lock(key); err != nil {
+		return err
+	}
+	defer cache.Unlock(key)
+
 	return VcsUpdate(d, m.force, m.updated)
 }
 
@@ -707,6 +802,22 @@ type VersionHandler struct {
 	// same. We are keeping track to only display them once.
 	// the parent pac
 	Conflicts map[string]bool
+
+	// Requirements are the versions each package asks for in the
+	// configuration imported from its checkout.
+	Requirements map[string][]Requirement
+
+	// Locked are the dependencies kept at their versions in the lock file.
+	Locked map[string]*cfg.Lock
+
+	// NestedLocks reads the glide.lock file of each package for the versions
+	// it was tested with. They are recorded as hints in Requirements and used
+	// while resolving.
+	NestedLocks bool
+
+	// Solved are the versions chosen by the solver on an earlier pass. The
+	// imports are resolved again at them.
+	Solved map[string]string
 }
 
 // Process imports dependencies for a package
@@ -722,12 +833,35 @@ func (d *VersionHandler) Process(pkg string) (e error) {
 	// Should we look in places other than the root of the project?
 	if d.Imported[root] == false {
 		d.Imported[root] = true
-		p := d.pkgPath(root)
+		p, err := d.pkgPath(root)
+		if err != nil {
+			msg.Err("Unable to import from %s. Err: %s", root, err)
+			return err
+		}
 		f, deps, err := importer.Import(p)
+		var hints map[string]string
+		if f && err == nil && d.NestedLocks {
+			hints = d.nestedLock(root, p)
+		}
 		if f && err == nil {
 			for _, dep := range deps {
+				if d.Requirements != nil {
+					d.Requirements[root] = append(d.Requirements[root], Requirement{Name: dep.Name, Reference: dep.Reference})
+				}
+				if h, ok := hints[dep.Name]; ok {
+					dep.Reference = h
+				}
+
+				// Only the glide.yaml file of the project can copy local
+				// directories into vendor/.
+				if dep.Path != "" {
+					msg.Warn("Ignoring the local path %s for %s in the configuration of %s", dep.Path, dep.Name, root)
+					dep.Path = ""
+				}
+				applyOverride(d.Config, dep, root)
 
-				// The fist one wins. Would something smater than this be better?
+				// The first one is used while resolving. The versions are
+				// chosen by the solver once all of the requirements are known.
 				exists, _ := d.Use.Get(dep.Name)
 				if exists == nil && (dep.Reference != "" || dep.Repository != "") {
 					d.Use.Add(dep.Name, dep, root)
@@ -743,10 +877,9 @@ func (d *VersionHandler) Process(pkg string) (e error) {
 }
 
 // SetVersion sets the version for a package. If that package version is already
-// set it handles the case by:
-// - keeping the already set version
-// - proviting messaging about the version conflict
-// TODO(mattfarina): The way version setting happens can be improved. Currently not optimal.
+// set the already set version is kept. The version is only used to resolve
+// the imports of the package. The solver chooses the final versions satisfying
+// all of the requirements after resolving.
 func (d *VersionHandler) SetVersion(pkg string, addTest bool) (e error) {
 	root := util.GetRootFromPackage(pkg)
 
@@ -779,8 +912,8 @@ func (d *VersionHandler) SetVersion(pkg string, addTest bool) (e error) {
 			v.Pin = ""
 			dep = v
 		} else if v.Reference != "" && dep.Reference != "" && v.Reference != dep.Reference {
-			dest := d.pkgPath(pkg)
-			dep = determineDependency(v, dep, dest, req)
+			msg.Debug("%s asks for %s %s. Using %s until the versions are solved", req, v.Name, dep.Reference, v.Reference)
+			dep = v
 		} else {
 			dep = v
 		}
@@ -811,22 +944,44 @@ func (d *VersionHandler) SetVersion(pkg string, addTest bool) (e error) {
 		}
 	}
 
-	err := VcsVersion(dep)
+	// Overrides win over the version asked for.
+	from := req
+	if dep == v {
+		from = ""
+	}
+	applyOverride(d.Config, dep, from)
+	if ver, ok := d.Solved[root]; ok && ver != dep.Reference {
+		dep.Reference = ver
+		dep.Pin = ""
+		dep.Hash = ""
+	}
+	if l, ok := d.Locked[root]; ok {
+		lockDependency(dep, l)
+	}
+
+	key, err := cache.Key(dep.Remote())
+	if err == nil {
+		err = cache.Lock(key)
+	}
+	if err == nil {
+		err = VcsVersion(dep)
+		cache.Unlock(key)
+	}
 	if err != nil {
-		msg.Warn("Unable to set version on %s to %s. Err: %s", root, dep.Reference, err)
+		msg.With(msg.Fields{"package": root, "version": dep.Reference, "action": "setting version"}).Warn("Unable to set version on %s to %s. Err: %s", root, dep.Reference, err)
 		e = err
 	}
 
 	return
 }
 
-func (d *VersionHandler) pkgPath(pkg string) string {
+func (d *VersionHandler) pkgPath(pkg string) (string, error) {
 	root, sub := util.NormalizeName(pkg)
 
 	// For the parent applications source skip the cache.
 	if root == d.Config.Name {
 		pth := gpath.Basepath()
-		return filepath.Join(pth, filepath.FromSlash(sub))
+		return filepath.Join(pth, filepath.FromSlash(sub)), nil
 	}
 
 	dep := d.Config.Imports.Get(root)
@@ -839,147 +994,20 @@ func (d *VersionHandler) pkgPath(pkg string) string {
 
 		if dep == nil {
 			dep = &cfg.Dependency{Name: root}
+			applyOverride(d.Config, dep, "")
 		}
 	}
 
-	key, err := cache.Key(dep.Remote())
-	if err != nil {
-		msg.Die("Error generating cache key for %s", dep.Name)
+	if dep.Path != "" {
+		return filepath.Join(localDir(dep), filepath.FromSlash(sub)), nil
 	}
 
-	return filepath.Join(cache.Location(), "src", key, filepath.FromSlash(sub))
-}
-
-func determineDependency(v, dep *cfg.Dependency, dest, req string) *cfg.Dependency {
-	repo, err := v.GetRepo(dest)
-	if err != nil {
-		singleWarn("Unable to access repo for %s\n", v.Name)
-		singleInfo("Keeping %s %s", v.Name, v.Reference)
-		return v
-	}
-
-	vIsRef := repo.IsReference(v.Reference)
-	depIsRef := repo.IsReference(dep.Reference)
-
-	// Both are references and they are different ones.
-	if vIsRef && depIsRef {
-		singleWarn("Conflict: %s rev is currently %s, but %s wants %s\n", v.Name, v.Reference, req, dep.Reference)
-
-		displayCommitInfo(repo, v)
-		displayCommitInfo(repo, dep)
-
-		singleInfo("Keeping %s %s", v.Name, v.Reference)
-		return v
-	} else if vIsRef {
-		// The current one is a reference and the suggestion is a SemVer constraint.
-		con, err := semver.NewConstraint(dep.Reference)
-		if err != nil {
-			singleWarn("Version issue for %s: '%s' is neither a reference or semantic version constraint\n", dep.Name, dep.Reference)
-			singleInfo("Keeping %s %s", v.Name, v.Reference)
-			return v
-		}
-
-		ver, err := semver.NewVersion(v.Reference)
-		if err != nil {
-			// The existing version is not a semantic version.
-			singleWarn("Conflict: %s version is %s, but also asked for %s\n", v.Name, v.Reference, dep.Reference)
-			displayCommitInfo(repo, v)
-			singleInfo("Keeping %s %s", v.Name, v.Reference)
-			return v
-		}
-
-		if con.Check(ver) {
-			singleInfo("Keeping %s %s because it fits constraint '%s'", v.Name, v.Reference, dep.Reference)
-			return v
-		}
-		singleWarn("Conflict: %s version is %s but does not meet constraint '%s'\n", v.Name, v.Reference, dep.Reference)
-		singleInfo("Keeping %s %s", v.Name, v.Reference)
-		return v
-	} else if depIsRef {
-
-		con, err := semver.NewConstraint(v.Reference)
-		if err != nil {
-			singleWarn("Version issue for %s: '%s' is neither a reference or semantic version constraint\n", v.Name, v.Reference)
-			singleInfo("Keeping %s %s", v.Name, v.Reference)
-			return v
-		}
-
-		ver, err := semver.NewVersion(dep.Reference)
-		if err != nil {
-			singleWarn("Conflict: %s version is %s, but also asked for %s\n", v.Name, v.Reference, dep.Reference)
-			displayCommitInfo(repo, dep)
-			singleInfo("Keeping %s %s", v.Name, v.Reference)
-			return v
-		}
-
-		if con.Check(ver) {
-			v.Reference = dep.Reference
-			singleInfo("Using %s %s because it fits constraint '%s'", v.Name, v.Reference, v.Reference)
-			return v
-		}
-		singleWarn("Conflict: %s semantic version constraint is %s but '%s' does not meet the constraint\n", v.Name, v.Reference, v.Reference)
-		singleInfo("Keeping %s %s", v.Name, v.Reference)
-		return v
-	}
-	// Neither is a vcs reference and both could be semantic version
-	// constraints that are different.
-
-	_, err = semver.NewConstraint(dep.Reference)
-	if err != nil {
-		// dd.Reference is not a reference or a valid constraint.
-		singleWarn("Version %s %s is not a reference or valid semantic version constraint\n", dep.Name, dep.Reference)
-		singleInfo("Keeping %s %s", v.Name, v.Reference)
-		return v
-	}
-
-	_, err = semver.NewConstraint(v.Reference)
+	key, err := cache.Key(dep.Remote())
 	if err != nil {
-		// existing.Reference is not a reference or a valid constraint.
-		// We really should never end up here.
-		singleWarn("Version %s %s is not a reference or valid semantic version constraint\n", v.Name, v.Reference)
-
-		v.Reference = dep.Reference
-		v.Pin = ""
-		singleInfo("Using %s %s because it is a valid version", v.Name, v.Reference)
-		return v
-	}
-
-	// Both versions are constraints. Try to merge them.
-	// If either comparison has an || skip merging. That's complicated.
-	ddor := strings.Index(dep.Reference, "||")
-	eor := strings.Index(v.Reference, "||")
-	if ddor == -1 && eor == -1 {
-		// Add the comparisons together.
-		newRef := v.Reference + ", " + dep.Reference
-		v.Reference = newRef
-		v.Pin = ""
-		singleInfo("Combining %s semantic version constraints %s and %s", v.Name, v.Reference, dep.Reference)
-		return v
-	}
-	singleWarn("Conflict: %s version is %s, but also asked for %s\n", v.Name, v.Reference, dep.Reference)
-	singleInfo("Keeping %s %s", v.Name, v.Reference)
-	return v
-}
-
-var warningMessage = make(map[string]bool)
-var infoMessage = make(map[string]bool)
-
-func singleWarn(ft string, v ...interface{}) {
-	m := fmt.Sprintf(ft, v...)
-	_, f := warningMessage[m]
-	if !f {
-		msg.Warn(m)
-		warningMessage[m] = true
+		return "", fmt.Errorf("Error generating cache key for %s: %s", dep.Name, err)
 	}
-}
 
-func singleInfo(ft string, v ...interface{}) {
-	m := fmt.Sprintf(ft, v...)
-	_, f := infoMessage[m]
-	if !f {
-		msg.Info(m)
-		infoMessage[m] = true
-	}
+	return filepath.Join(cache.Location(), "src", key, filepath.FromSlash(sub)), nil
 }
 
 type importCache struct {
@@ -1007,33 +1035,3 @@ func (i *importCache) Add(name string, dep *cfg.Dependency, root string) {
 	i.cache[name] = dep
 	i.from[name] = root
 }
-
-var displayCommitInfoPrefix = msg.Default.Color(msg.Green, "[INFO] ")
-var displayCommitInfoTemplate = "%s reference %s:\n" +
-	displayCommitInfoPrefix + "- author: %s\n" +
-	displayCommitInfoPrefix + "- commit date: %s\n" +
-	displayCommitInfoPrefix + "- subject (first line): %s\n"
-
-func displayCommitInfo(repo vcs.Repo, dep *cfg.Dependency) {
-	c, err := repo.CommitInfo(dep.Reference)
-	ref := dep.Reference
-
-	if err == nil {
-		tgs, err2 := repo.TagsFromCommit(c.Commit)
-		if err2 == nil && len(tgs) > 0 {
-			if tgs[0] != dep.Reference {
-				ref = ref + " (" + tgs[0] + ")"
-			}
-		}
-		singleInfo(displayCommitInfoTemplate, dep.Name, ref, c.Author, c.Date.Format(time.RFC1123Z), commitSubjectFirstLine(c.Message))
-	}
-}
-
-func commitSubjectFirstLine(sub string) string {
-	lines := strings.Split(sub, "\n")
-	if len(lines) <= 1 {
-		return sub
-	}
-
-	return lines[0]
-}
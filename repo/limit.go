@@ -0,0 +1,187 @@
+package repo
+
+import (
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	cp "github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/codegangsta/cli"
+)
+
+// defaultWorkers is the number of workers used when no number of jobs is set.
+const defaultWorkers = 20
+
+var (
+	limitLock sync.Mutex
+
+	// hostJobs is the number of fetches allowed from one host at the same
+	// time. Zero is no limit.
+	hostJobs  int
+	hostSlots = make(map[string]chan struct{})
+
+	// fetches limits the rate fetches are started at. It is nil when there is
+	// no limit.
+	fetches *tokenBucket
+)
+
+// SetConcurrency sets the number of workers used for concurrent operations,
+// the number of fetches allowed from one host at the same time, and the rate
+// fetches are started at. Zero values use the defaults of 20 workers and no
+// limits on fetches.
+func SetConcurrency(c *cfg.Concurrency) {
+	if c == nil {
+		c = &cfg.Concurrency{}
+	}
+
+	limitLock.Lock()
+	defer limitLock.Unlock()
+
+	concurrentWorkers = defaultWorkers
+	if c.Jobs > 0 {
+		concurrentWorkers = c.Jobs
+	}
+	hostJobs = c.HostJobs
+	hostSlots = make(map[string]chan struct{})
+	fetches = nil
+	if c.FetchRate > 0 {
+		fetches = newTokenBucket(c.FetchRate)
+	}
+}
+
+// concurrently runs fn for each of the dependencies using the configured
+// number of workers. The errors returned by fn are combined.
+func concurrently(deps []*cfg.Dependency, fn func(*cfg.Dependency) error) error {
+	in := make(chan *cfg.Dependency)
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var returnErr error
+
+	workers := concurrentWorkers
+	if workers > len(deps) {
+		workers = len(deps)
+	}
+	for ii := 0; ii < workers; ii++ {
+		wg.Add(1)
+		go func(ch <-chan *cfg.Dependency) {
+			defer wg.Done()
+			for dep := range ch {
+				if err := fn(dep); err != nil {
+					// Capture the error while making sure the concurrent
+					// operations don't step on each other.
+					lock.Lock()
+					if returnErr == nil {
+						returnErr = err
+					} else {
+						returnErr = cli.NewMultiError(returnErr, err)
+					}
+					lock.Unlock()
+				}
+			}
+		}(in)
+	}
+
+	for _, dep := range deps {
+		in <- dep
+	}
+	close(in)
+	wg.Wait()
+
+	return returnErr
+}
+
+// acquireFetch waits until a dependency may be fetched under the per host and
+// rate limits. The returned function must be called when the fetch is done.
+func acquireFetch(dep *cfg.Dependency) func() {
+	if cp.Offline {
+		return func() {}
+	}
+
+	limitLock.Lock()
+	var slot chan struct{}
+	if hostJobs > 0 {
+		remote := dep.Remote()
+		if f, ok := fetcherFor(dep).(*proxyFetcher); ok {
+			remote = f.base
+		}
+		h := remoteHost(remote)
+		slot = hostSlots[h]
+		if slot == nil {
+			slot = make(chan struct{}, hostJobs)
+			hostSlots[h] = slot
+		}
+	}
+	bucket := fetches
+	limitLock.Unlock()
+
+	if slot != nil {
+		slot <- struct{}{}
+	}
+	if bucket != nil {
+		bucket.take()
+	}
+
+	return func() {
+		if slot != nil {
+			<-slot
+		}
+	}
+}
+
+// scpSyntaxRe matches the SCP-like addresses used to access repos over SSH.
+var scpSyntaxRe = regexp.MustCompile(`^([a-zA-Z0-9_]+)@([a-zA-Z0-9._-]+):(.*)$`)
+
+// remoteHost returns the host of a URL or SCP style location such as
+// git@example.com:foo/bar.git.
+func remoteHost(remote string) string {
+	if u, err := url.Parse(remote); err == nil && u.Host != "" {
+		return u.Host
+	}
+	if m := scpSyntaxRe.FindStringSubmatch(remote); m != nil {
+		return m[2]
+	}
+
+	return strings.SplitN(remote, "/", 2)[0]
+}
+
+// tokenBucket allows a number of events per second with bursts of up to the
+// same number.
+type tokenBucket struct {
+	sync.Mutex
+	rate   float64
+	size   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	size := math.Max(1, math.Ceil(rate))
+	return &tokenBucket{
+		rate:   rate,
+		size:   size,
+		tokens: size,
+		last:   time.Now(),
+	}
+}
+
+// take waits for a token and removes it from the bucket.
+func (b *tokenBucket) take() {
+	for {
+		b.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.size, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.Unlock()
+			return
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.Unlock()
+		time.Sleep(wait)
+	}
+}
@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/codegangsta/cli"
+)
+
+func TestConcurrently(t *testing.T) {
+	SetConcurrency(&cfg.Concurrency{Jobs: 2})
+	defer SetConcurrency(nil)
+
+	var deps []*cfg.Dependency
+	for _, n := range []string{"a", "b", "c", "d", "e"} {
+		deps = append(deps, &cfg.Dependency{Name: n})
+	}
+
+	var lock sync.Mutex
+	running, most, seen := 0, 0, 0
+	err := concurrently(deps, func(dep *cfg.Dependency) error {
+		lock.Lock()
+		running++
+		seen++
+		if running > most {
+			most = running
+		}
+		lock.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+
+		if dep.Name == "b" || dep.Name == "d" {
+			return errors.New("failed " + dep.Name)
+		}
+		return nil
+	})
+
+	if seen != 5 {
+		t.Errorf("Expected all 5 dependencies to be handled but got %d", seen)
+	}
+	if most > 2 {
+		t.Errorf("Expected at most 2 workers but %d ran at the same time", most)
+	}
+	me, ok := err.(cli.MultiError)
+	if !ok || len(me.Errors) != 2 {
+		t.Errorf("Expected both errors to be returned but got %v", err)
+	}
+}
+
+func TestAcquireFetch(t *testing.T) {
+	SetConcurrency(&cfg.Concurrency{HostJobs: 1})
+	defer SetConcurrency(nil)
+
+	a := &cfg.Dependency{Name: "example.com/a"}
+	b := &cfg.Dependency{Name: "example.com/b"}
+	c := &cfg.Dependency{Name: "example.org/c"}
+
+	release := acquireFetch(a)
+
+	// Another host is not limited.
+	acquireFetch(c)()
+
+	acquired := make(chan struct{})
+	go func() {
+		acquireFetch(b)()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Expected the second fetch from example.com to wait")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the second fetch from example.com to start after the first")
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(50)
+	start := time.Now()
+	for i := 0; i < 55; i++ {
+		b.take()
+	}
+
+	// The first 50 are a burst and the next 5 take 20ms each.
+	if d := time.Since(start); d < 80*time.Millisecond {
+		t.Errorf("Expected the rate to be limited but 55 tokens took %s", d)
+	}
+}
+
+func TestRemoteHost(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/Masterminds/glide": "github.com",
+		"git@example.com:foo/bar.git":          "example.com",
+		"ssh://git@example.com:2222/foo":       "example.com:2222",
+		"example.com/foo":                      "example.com",
+	}
+	for remote, host := range tests {
+		if h := remoteHost(remote); h != host {
+			t.Errorf("Expected the host %s for %s but got %s", host, remote, h)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Masterminds/glide/cfg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/vcs"
+)
+
+// localDir returns the directory of a dependency with a local path. Relative
+// paths are relative to the directory of the glide.yaml file.
+func localDir(dep *cfg.Dependency) string {
+	p := filepath.FromSlash(dep.Path)
+	if filepath.IsAbs(p) {
+		return p
+	}
+
+	base := gpath.Basepath()
+	if gf, err := gpath.Glide(); err == nil {
+		base = filepath.Dir(gf)
+	}
+
+	return filepath.Join(base, p)
+}
+
+// checkLocal makes sure the directory of a local dependency exists.
+func checkLocal(dep *cfg.Dependency) error {
+	dir := localDir(dep)
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("Unable to use the local path %s for %s: %s", dep.Path, dep.Name, err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("Unable to use the local path %s for %s: not a directory", dep.Path, dep.Name)
+	}
+
+	return nil
+}
+
+// localVersion returns the checked out revision of a local dependency that is
+// a VCS checkout. Other directories have no version.
+func localVersion(dep *cfg.Dependency) string {
+	repo, err := vcs.NewRepo("", localDir(dep))
+	if err != nil {
+		return ""
+	}
+	ver, err := repo.Version()
+	if err != nil {
+		return ""
+	}
+
+	return ver
+}
+
+// copyLocal copies the directory of a local dependency to dest, skipping the
+// VCS metadata.
+func copyLocal(dep *cfg.Dependency, dest string) error {
+	src := localDir(dep)
+
+	return filepath.Walk(src, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		if fi.IsDir() {
+			switch fi.Name() {
+			case ".git", ".hg", ".bzr", ".svn":
+				if rel != "." {
+					return filepath.SkipDir
+				}
+			}
+			return os.MkdirAll(target, fi.Mode()|0700)
+		}
+
+		return gpath.CopyFile(p, target)
+	})
+}
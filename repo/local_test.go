@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+	gpath "github.com/Masterminds/glide/path"
+)
+
+func TestNestedLocalPathIgnored(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "glide-local-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	home := gpath.Home()
+	gpath.SetHome(filepath.Join(tmp, "home"))
+	defer gpath.SetHome(home)
+
+	// A local dependency of the project asks for another local directory.
+	for _, d := range []string{"a", "secret"} {
+		if err := os.MkdirAll(filepath.Join(tmp, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	yml := "package: example.com/a\nimport:\n- package: example.com/secret\n  version: master\n  path: ../secret\n"
+	if err := ioutil.WriteFile(filepath.Join(tmp, "a", "glide.yaml"), []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &cfg.Config{
+		Name:    "example.com/app",
+		Imports: cfg.Dependencies{{Name: "example.com/a", Path: filepath.Join(tmp, "a")}},
+	}
+	v := &VersionHandler{
+		Use:       newImportCache(),
+		Imported:  make(map[string]bool),
+		Conflicts: make(map[string]bool),
+		Config:    conf,
+	}
+	if err := v.Process("example.com/a"); err != nil {
+		t.Fatalf("Unexpected error processing: %s", err)
+	}
+	v.SetVersion("example.com/secret", false)
+
+	dep := conf.Imports.Get("example.com/secret")
+	if dep == nil {
+		t.Fatal("Expected example.com/secret to be added to the imports")
+	}
+	if dep.Path != "" {
+		t.Errorf("Expected the local path in the glide.yaml file of a dependency to be ignored but got %s", dep.Path)
+	}
+	if dep.Reference != "master" {
+		t.Errorf("Expected the version master to be kept but got %s", dep.Reference)
+	}
+}
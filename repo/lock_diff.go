@@ -0,0 +1,278 @@
+package repo
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cp "github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/semver"
+	v "github.com/Masterminds/vcs"
+)
+
+// The kinds of change to a dependency between two lock files.
+const (
+	LockAdded       = "added"
+	LockRemoved     = "removed"
+	LockUpgraded    = "upgraded"
+	LockDowngraded  = "downgraded"
+	LockChanged     = "changed"
+	LockRepoChanged = "repo-changed"
+)
+
+// maxLogCommits is the most commits displayed for a changed dependency.
+const maxLogCommits = 20
+
+// LockChange is a change to a dependency between two lock files. The tags are
+// the semantic version tags of the revisions in the cached repository.
+type LockChange struct {
+	Name       string `json:"name"`
+	Change     string `json:"change"`
+	OldVersion string `json:"oldVersion,omitempty"`
+	OldTag     string `json:"oldTag,omitempty"`
+	NewVersion string `json:"newVersion,omitempty"`
+	NewTag     string `json:"newTag,omitempty"`
+	OldRepo    string `json:"oldRepo,omitempty"`
+	NewRepo    string `json:"newRepo,omitempty"`
+
+	// dir is the location of the cached repository of the new version.
+	dir string
+}
+
+// DiffLocks compares two lock files and returns the changed dependencies
+// sorted by name. The cached repositories are used to find tags and whether
+// a revision is newer. Nothing is fetched.
+func DiffLocks(old, new *cfg.Lockfile) []*LockChange {
+	olds := lockMap(old)
+	news := lockMap(new)
+
+	var changes []*LockChange
+	for name, n := range news {
+		o, ok := olds[name]
+		if !ok {
+			c := &LockChange{Name: name, Change: LockAdded, NewVersion: n.Version, NewRepo: lockRemote(n)}
+			c.dir = lockDir(n)
+			c.NewTag = revisionTag(c.dir, n.Version)
+			changes = append(changes, c)
+			continue
+		}
+
+		if lockRemote(o) == lockRemote(n) && o.VcsType == n.VcsType && o.Version == n.Version {
+			continue
+		}
+
+		c := &LockChange{
+			Name:       name,
+			OldVersion: o.Version,
+			NewVersion: n.Version,
+			OldRepo:    lockRemote(o),
+			NewRepo:    lockRemote(n),
+			dir:        lockDir(n),
+		}
+		c.OldTag = revisionTag(lockDir(o), o.Version)
+		c.NewTag = revisionTag(c.dir, n.Version)
+		if c.OldRepo != c.NewRepo || o.VcsType != n.VcsType {
+			c.Change = LockRepoChanged
+		} else {
+			c.Change = compareRevisions(c)
+		}
+		changes = append(changes, c)
+	}
+
+	for name, o := range olds {
+		if _, ok := news[name]; !ok {
+			changes = append(changes, &LockChange{Name: name, Change: LockRemoved, OldVersion: o.Version, OldRepo: lockRemote(o)})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+func lockMap(l *cfg.Lockfile) map[string]*cfg.Lock {
+	m := make(map[string]*cfg.Lock)
+	if l == nil {
+		return m
+	}
+	for _, lk := range l.Imports {
+		m[lk.Name] = lk
+	}
+	for _, lk := range l.DevImports {
+		if _, ok := m[lk.Name]; !ok {
+			m[lk.Name] = lk
+		}
+	}
+
+	return m
+}
+
+// lockRemote returns where a locked dependency comes from.
+func lockRemote(l *cfg.Lock) string {
+	if l.Path != "" {
+		return l.Path
+	}
+
+	return cfg.DependencyFromLock(l).Remote()
+}
+
+// lockDir returns the location of the cached repository of a locked
+// dependency or an empty string when it is not in the cache.
+func lockDir(l *cfg.Lock) string {
+	if l.Path != "" || l.Archive != "" {
+		return ""
+	}
+	key, err := cp.Key(cfg.DependencyFromLock(l).Remote())
+	if err != nil {
+		return ""
+	}
+	dir := filepath.Join(cp.Location(), "src", key)
+	if _, err := v.DetectVcsFromFS(dir); err != nil {
+		return ""
+	}
+
+	return dir
+}
+
+// revisionTag returns the highest semantic version tag on a revision in a
+// cached repository.
+func revisionTag(dir, rev string) string {
+	if dir == "" || rev == "" {
+		return ""
+	}
+	repo, err := v.NewRepo("", dir)
+	if err != nil {
+		return ""
+	}
+	tags, err := repo.TagsFromCommit(rev)
+	if err != nil {
+		return ""
+	}
+
+	var best *semver.Version
+	var tag string
+	for _, t := range tags {
+		sv, err := semver.NewVersion(t)
+		if err != nil {
+			continue
+		}
+		if best == nil || sv.GreaterThan(best) {
+			best, tag = sv, t
+		}
+	}
+
+	return tag
+}
+
+// compareRevisions returns whether the new revision of a dependency is an
+// upgrade or a downgrade. Semantic versions are compared first and then the
+// history of cached Git repositories.
+func compareRevisions(c *LockChange) string {
+	ov, oerr := semver.NewVersion(c.OldTag)
+	nv, nerr := semver.NewVersion(c.NewTag)
+	if oerr == nil && nerr == nil && !ov.Equal(nv) {
+		if nv.GreaterThan(ov) {
+			return LockUpgraded
+		}
+		return LockDowngraded
+	}
+
+	repo, err := gitRepo(c.dir)
+	if err != nil {
+		return LockChanged
+	}
+	if _, err := repo.RunFromDir("git", "merge-base", "--is-ancestor", c.OldVersion, c.NewVersion); err == nil {
+		return LockUpgraded
+	}
+	if _, err := repo.RunFromDir("git", "merge-base", "--is-ancestor", c.NewVersion, c.OldVersion); err == nil {
+		return LockDowngraded
+	}
+
+	return LockChanged
+}
+
+func gitRepo(dir string) (v.Repo, error) {
+	if dir == "" {
+		return nil, v.ErrCannotDetectVCS
+	}
+	if t, err := v.DetectVcsFromFS(dir); err != nil || t != v.Git {
+		return nil, v.ErrWrongVCS
+	}
+
+	return v.NewGitRepo("", dir)
+}
+
+// DisplayLockChangeLog displays the commits between the old and new versions
+// of an upgraded or downgraded dependency. Only Git repositories in the cache
+// are supported.
+func DisplayLockChangeLog(c *LockChange) {
+	from, to := c.OldVersion, c.NewVersion
+	switch c.Change {
+	case LockUpgraded:
+	case LockDowngraded:
+		from, to = to, from
+	default:
+		return
+	}
+
+	repo, err := gitRepo(c.dir)
+	if err != nil {
+		return
+	}
+	out, err := repo.RunFromDir("git", "rev-list", "--max-count", strconv.Itoa(maxLogCommits+1), from+".."+to)
+	if err != nil {
+		msg.Debug("Unable to list the commits of %s between %s and %s: %s", c.Name, from, to, err)
+		return
+	}
+	commits := strings.Fields(string(out))
+	if len(commits) == 0 {
+		return
+	}
+
+	if c.Change == LockDowngraded {
+		msg.Info("Commits of %s removed by the downgrade:", c.Name)
+	} else {
+		msg.Info("Commits of %s added by the upgrade:", c.Name)
+	}
+	for i, commit := range commits {
+		if i == maxLogCommits {
+			msg.Info("... and more commits")
+			break
+		}
+		displayCommitInfo(repo, c.Name, commit)
+	}
+}
+
+func displayCommitInfo(repo v.Repo, name, ref string) {
+	c, err := repo.CommitInfo(ref)
+	if err != nil {
+		return
+	}
+
+	tgs, err := repo.TagsFromCommit(c.Commit)
+	if err == nil && len(tgs) > 0 && tgs[0] != ref {
+		ref = ref + " (" + tgs[0] + ")"
+	}
+
+	// The prefix is colored when displayed so --no-color is respected.
+	prefix := msg.Default.Color(msg.Green, "[INFO]\t")
+	tpl := "%s reference %s:\n" +
+		prefix + "- author: %s\n" +
+		prefix + "- commit date: %s\n" +
+		prefix + "- subject (first line): %s\n"
+	msg.Info(tpl, name, ref, c.Author, c.Date.Format(time.RFC1123Z), commitSubjectFirstLine(c.Message))
+}
+
+func commitSubjectFirstLine(sub string) string {
+	lines := strings.Split(sub, "\n")
+	if len(lines) <= 1 {
+		return sub
+	}
+
+	return lines[0]
+}
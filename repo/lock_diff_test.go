@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	gpath "github.com/Masterminds/glide/path"
+)
+
+func TestDiffLocks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	tmp, err := ioutil.TempDir("", "glide-lock-diff-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	home := gpath.Home()
+	gpath.SetHome(tmp)
+	defer gpath.SetHome(home)
+
+	key, err := cache.Key("https://example.com/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(cache.Location(), "src", key)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %s %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(m string) string {
+		git("-c", "user.name=glide", "-c", "user.email=glide@example.com", "commit", "-q", "--allow-empty", "-m", m)
+		return git("rev-parse", "HEAD")
+	}
+	git("init", "-q")
+	git("remote", "add", "origin", "https://example.com/foo")
+	v1 := commit("First")
+	git("tag", "v1.0.0")
+	mid := commit("Second")
+	v2 := commit("Third")
+	git("tag", "v1.1.0")
+	git("checkout", "-q", "-b", "other", v1)
+	other := commit("Other")
+
+	lock := func(imports ...*cfg.Lock) *cfg.Lockfile {
+		return &cfg.Lockfile{Imports: imports}
+	}
+	foo := func(rev string) *cfg.Lock {
+		return &cfg.Lock{Name: "example.com/foo", Repository: "https://example.com/foo", Version: rev}
+	}
+
+	tests := []struct {
+		old, new *cfg.Lockfile
+		change   string
+		oldTag   string
+		newTag   string
+	}{
+		{lock(foo(v1)), lock(foo(v2)), LockUpgraded, "v1.0.0", "v1.1.0"},
+		{lock(foo(v2)), lock(foo(v1)), LockDowngraded, "v1.1.0", "v1.0.0"},
+		{lock(foo(v1)), lock(foo(mid)), LockUpgraded, "v1.0.0", ""},
+		{lock(foo(v2)), lock(foo(other)), LockChanged, "v1.1.0", ""},
+		{lock(), lock(foo(v1)), LockAdded, "", "v1.0.0"},
+		{lock(foo(v1)), lock(), LockRemoved, "", ""},
+		{lock(&cfg.Lock{Name: "example.com/foo", Repository: "https://example.com/fork", Version: v1}), lock(foo(v1)), LockRepoChanged, "", "v1.0.0"},
+	}
+	for i, tt := range tests {
+		changes := DiffLocks(tt.old, tt.new)
+		if len(changes) != 1 {
+			t.Errorf("%d: Expected one change but got %d", i, len(changes))
+			continue
+		}
+		c := changes[0]
+		if c.Change != tt.change || c.OldTag != tt.oldTag || c.NewTag != tt.newTag {
+			t.Errorf("%d: Expected %s from %q to %q but got %s from %q to %q", i, tt.change, tt.oldTag, tt.newTag, c.Change, c.OldTag, c.NewTag)
+		}
+	}
+
+	if changes := DiffLocks(lock(foo(v1)), lock(foo(v1))); len(changes) != 0 {
+		t.Errorf("Expected no changes but got %v", changes)
+	}
+}
@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+)
+
+// KeepLocked keeps the dependencies in a lock file at their locked versions
+// while updating, except for the packages named in update. Local and archive
+// dependencies have no versions to keep.
+func (i *Installer) KeepLocked(lock *cfg.Lockfile, update []string) {
+	i.Locked = make(map[string]*cfg.Lock)
+	for _, l := range append(append(cfg.Locks{}, lock.Imports...), lock.DevImports...) {
+		if l.Path != "" || l.Archive != "" || l.Version == "" {
+			continue
+		}
+		named := false
+		for _, u := range update {
+			if u == l.Name {
+				named = true
+				break
+			}
+		}
+		if !named {
+			i.Locked[l.Name] = l
+		}
+	}
+}
+
+// applyLocked sets the dependencies of a config kept at their locked versions
+// to those versions.
+func (i *Installer) applyLocked(conf *cfg.Config) {
+	for _, d := range conf.Imports {
+		if l, ok := i.Locked[d.Name]; ok {
+			lockDependency(d, l)
+		}
+	}
+	for _, d := range conf.DevImports {
+		if l, ok := i.Locked[d.Name]; ok {
+			lockDependency(d, l)
+		}
+	}
+}
+
+// lockDependency sets a dependency to its version in the lock file.
+func lockDependency(dep *cfg.Dependency, l *cfg.Lock) {
+	ld := cfg.DependencyFromLock(l)
+	if dep.Pin != ld.Reference {
+		msg.Debug("--> Keeping %s at %s from glide.lock", dep.Name, ld.Reference)
+	}
+	dep.Reference = ld.Reference
+	dep.Pin = ld.Reference
+	dep.Hash = ld.Hash
+	if ld.Repository != "" {
+		dep.Repository = ld.Repository
+	}
+	if ld.VcsType != "" {
+		dep.VcsType = ld.VcsType
+	}
+}
+
+// lockedRequirements returns the requirements keeping dependencies at their
+// locked versions. A semantic version tag on a locked revision is used so it
+// can be checked against the version ranges asked for.
+func (i *Installer) lockedRequirements() []Requirement {
+	var reqs []Requirement
+	for name, l := range i.Locked {
+		ref := l.Version
+		if t := revisionTag(lockDir(l), l.Version); t != "" {
+			ref = t
+		}
+		reqs = append(reqs, Requirement{Name: name, Reference: ref, Locked: true})
+	}
+	sort.Slice(reqs, func(a, b int) bool {
+		return reqs[a].Name < reqs[b].Name
+	})
+
+	return reqs
+}
+
+// lockedConflict explains a conflict caused by keeping dependencies at their
+// locked versions. Other errors are returned as they are.
+func (i *Installer) lockedConflict(err error) error {
+	cerr, ok := err.(*ConflictError)
+	if !ok || len(i.Locked) == 0 {
+		return err
+	}
+
+	seen := map[string]bool{}
+	var kept []string
+	for _, r := range cerr.Requirements {
+		name := ""
+		if r.Locked {
+			name = r.Name
+		} else if _, ok := i.Locked[r.From]; ok {
+			name = r.From
+		}
+		if name != "" && !seen[name] {
+			seen[name] = true
+			kept = append(kept, name)
+		}
+	}
+	if len(kept) == 0 {
+		return err
+	}
+
+	return fmt.Errorf("%s\nUpdating the selected packages requires changing %s from the version in glide.lock. Add it to the packages to update, or update all of the packages", cerr, strings.Join(kept, ", "))
+}
@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+)
+
+// nestedLockHints returns the versions in the glide.lock file of a package as
+// hints for its dependencies. A semantic version tag on a locked revision is
+// used when the dependency is in the cache so it can be compared with the
+// version ranges asked for. Local and archive dependencies are skipped.
+func nestedLockHints(name string, yml []byte) []Requirement {
+	lock, err := cfg.LockfileFromYaml(yml)
+	if err != nil {
+		msg.Debug("Unable to read the glide.lock file of %s: %s", name, err)
+		return nil
+	}
+
+	var hints []Requirement
+	for _, l := range lock.Imports {
+		if l.Path != "" || l.Archive != "" || l.Version == "" {
+			continue
+		}
+		ref := l.Version
+		if t := revisionTag(lockDir(l), l.Version); t != "" {
+			ref = t
+		}
+		hints = append(hints, Requirement{Name: l.Name, Reference: ref, Hint: true})
+	}
+
+	return hints
+}
+
+// nestedLock records the versions in the glide.lock file in the checkout of a
+// package as hints and returns them by the name of the dependency.
+func (d *VersionHandler) nestedLock(name, dir string) map[string]string {
+	yml, err := ioutil.ReadFile(filepath.Join(dir, gpath.LockFile))
+	if err != nil {
+		return nil
+	}
+
+	hints := nestedLockHints(name, yml)
+	refs := make(map[string]string, len(hints))
+	for _, h := range hints {
+		msg.Debug("--> %s locks %s at %s", name, h.Name, h.Reference)
+		refs[h.Name] = h.Reference
+	}
+	if d.Requirements != nil {
+		d.Requirements[name] = append(d.Requirements[name], hints...)
+	}
+
+	return refs
+}
@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	cp "github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/util"
+)
+
+var (
+	offlineLock sync.Mutex
+	offlineConf *cfg.Config
+)
+
+// SetOffline turns offline mode on or off. In offline mode only the
+// repositories in the cache are used, including to find the root of packages.
+func SetOffline(on bool) {
+	cp.Offline = on
+	cfg.Offline = on
+	if on {
+		util.CachedRoot = cachedRoot
+	} else {
+		util.CachedRoot = nil
+	}
+}
+
+// SetOfflineConfig records the glide.yaml file of the project so the
+// repositories and overrides set in it are used to find packages in the cache
+// in offline mode.
+func SetOfflineConfig(conf *cfg.Config) {
+	offlineLock.Lock()
+	defer offlineLock.Unlock()
+	offlineConf = conf
+}
+
+// cachedRoot finds the root of a package by looking for its repository in
+// the cache. Each possible root uses the repository set for it in the
+// glide.yaml file, if any, and mirrors to find its location in the cache.
+func cachedRoot(pkg string) (string, bool) {
+	offlineLock.Lock()
+	conf := offlineConf
+	offlineLock.Unlock()
+
+	parts := strings.Split(pkg, "/")
+	for i := 1; i <= len(parts); i++ {
+		root := strings.Join(parts[:i], "/")
+		dep := &cfg.Dependency{Name: root}
+		if conf != nil {
+			if d := conf.Imports.Get(root); d != nil {
+				dep = d.Clone()
+			} else if d := conf.DevImports.Get(root); d != nil {
+				dep = d.Clone()
+			}
+			applyOverride(conf, dep, "")
+		}
+		if dep.Path != "" {
+			continue
+		}
+
+		key, err := cp.Key(dep.Remote())
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(cp.Location(), "src", key)); err == nil {
+			return root, true
+		}
+	}
+
+	return "", false
+}
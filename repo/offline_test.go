@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cp "github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/mirrors"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/util"
+)
+
+func TestCachedRoot(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "glide-offline-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	home := gpath.Home()
+	gpath.SetHome(tmp)
+	defer func() {
+		ioutil.WriteFile(filepath.Join(tmp, "mirrors.yaml"), []byte("repos: []\n"), 0644)
+		mirrors.Load()
+		gpath.SetHome(home)
+	}()
+	mirrorsYaml := "repos:\n- original: https://vanity.example.com/mirrored\n  repo: https://git.example.com/mirror\n"
+	if err := ioutil.WriteFile(filepath.Join(tmp, "mirrors.yaml"), []byte(mirrorsYaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := mirrors.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, remote := range []string{
+		"https://git.example.com/fork.git",
+		"https://git.example.com/mirror",
+		"https://git.example.com/over.git",
+		"https://vanity.example.com/plain",
+	} {
+		key, err := cp.Key(remote)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(cp.Location(), "src", key), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf := &cfg.Config{
+		Name:      "example.com/app",
+		Imports:   cfg.Dependencies{{Name: "vanity.example.com/forked", Repository: "https://git.example.com/fork.git"}},
+		Overrides: cfg.Dependencies{{Name: "vanity.example.com/over", Repository: "https://git.example.com/over.git"}},
+	}
+	SetOffline(true)
+	defer SetOffline(false)
+	SetOfflineConfig(conf)
+	defer SetOfflineConfig(nil)
+
+	tests := map[string]string{
+		"vanity.example.com/forked/sub/pkg": "vanity.example.com/forked",
+		"vanity.example.com/mirrored/sub":   "vanity.example.com/mirrored",
+		"vanity.example.com/over/x":         "vanity.example.com/over",
+		"vanity.example.com/plain/a/b":      "vanity.example.com/plain",
+		"vanity.example.com/missing/x":      "vanity.example.com/missing/x",
+	}
+	for pkg, want := range tests {
+		if got := util.GetRootFromPackage(pkg); got != want {
+			t.Errorf("Expected the root %s for %s but got %s", want, pkg, got)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package repo
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/semver"
+)
+
+// VersionInfo describes the locked version of a dependency along with the
+// newer versions available in its repository.
+type VersionInfo struct {
+	Name string `json:"name"`
+
+	// Reference is the version constraint from the glide.yaml file.
+	Reference string `json:"reference,omitempty"`
+
+	// Locked is the revision from the glide.lock file and LockedTag the
+	// semantic version tag pointing to it, when there is one.
+	Locked    string `json:"locked,omitempty"`
+	LockedTag string `json:"lockedTag,omitempty"`
+
+	// Wanted is the newest version satisfying Reference. It is only set
+	// when Reference is a semantic version constraint.
+	Wanted    string `json:"wanted,omitempty"`
+	WantedRev string `json:"wantedRev,omitempty"`
+
+	// Latest is the newest semantic version tag, ignoring Reference and
+	// pre-releases.
+	Latest string `json:"latest,omitempty"`
+
+	Outdated bool `json:"outdated"`
+
+	// NotLocked is true when the dependency is missing from the glide.lock
+	// file. It is not reported as outdated.
+	NotLocked bool `json:"notLocked,omitempty"`
+}
+
+// DependencyVersions updates the cached repository of a dependency and
+// returns the versions available for it compared to the locked revision.
+// Local and archive dependencies have no repository and are never outdated.
+func DependencyVersions(dep *cfg.Dependency, locked string) (*VersionInfo, error) {
+	// Local and archive dependencies have no versions to compare.
+	if dep.Path != "" || dep.Archive != "" {
+		return &VersionInfo{Name: dep.Name, Locked: locked, NotLocked: locked == ""}, nil
+	}
+
+	key, err := cache.Key(dep.Remote())
+	if err != nil {
+		return nil, fmt.Errorf("Cache key generation error: %s", err)
+	}
+	if err := cache.Lock(key); err != nil {
+		return nil, err
+	}
+	defer cache.Unlock(key)
+
+	// Snapshots from a proxy are compared with the versions on the proxy.
+	// They are not updated here.
+	dir := filepath.Join(cache.Location(), "src", key)
+	if !isSnapshot(dir) {
+		if err = VcsGet(dep); err != nil {
+			return nil, err
+		}
+	}
+
+	var refs []string
+	var isReference func(string) bool
+	var commit func(string) string
+	if isSnapshot(dir) {
+		refs, isReference, commit = snapshotVersions(dep.Name, dir)
+	} else {
+		repo, err := dep.GetRepo(dir)
+		if err != nil {
+			return nil, err
+		}
+		refs, err = getAllVcsRefs(repo)
+		if err != nil {
+			return nil, err
+		}
+		isReference = repo.IsReference
+		commit = func(ref string) string {
+			if ci, err := repo.CommitInfo(ref); err == nil {
+				return ci.Commit
+			}
+			return ""
+		}
+	}
+
+	var constraint *semver.Constraints
+	if dep.Reference != "" && (!isReference(dep.Reference) || strings.HasPrefix(dep.Reference, "^")) {
+		constraint, err = semver.NewConstraint(dep.Reference)
+		if err != nil {
+			return nil, fmt.Errorf("The reference '%s' is not valid: %s", dep.Reference, err)
+		}
+	}
+
+	vi := &VersionInfo{
+		Name:      dep.Name,
+		Reference: dep.Reference,
+		Locked:    locked,
+	}
+
+	// Tags are resolved to revisions to match them with the locked one.
+	revs := map[string]string{}
+	rev := func(v *semver.Version) string {
+		r, ok := revs[v.Original()]
+		if !ok {
+			r = commit(v.Original())
+			revs[v.Original()] = r
+		}
+		return r
+	}
+
+	semvers := getSemVers(refs)
+	sort.Sort(sort.Reverse(semver.Collection(semvers)))
+	var lockedTag *semver.Version
+	if locked != "" {
+		for _, v := range semvers {
+			if rev(v) == locked {
+				lockedTag = v
+				break
+			}
+		}
+	}
+
+	wanted, latest := newestVersions(semvers, constraint)
+	if lockedTag != nil {
+		vi.LockedTag = lockedTag.Original()
+	}
+	if wanted != nil {
+		vi.Wanted = wanted.Original()
+		vi.WantedRev = rev(wanted)
+	}
+	if latest != nil {
+		vi.Latest = latest.Original()
+	}
+
+	vi.NotLocked = locked == ""
+	vi.Outdated = isOutdated(locked, vi.WantedRev, lockedTag, wanted, latest)
+
+	return vi, nil
+}
+
+// isOutdated returns true when the locked revision is not the wanted one or
+// there is a newer version than the wanted or locked ones. A dependency that
+// is not locked is not outdated.
+func isOutdated(locked, wantedRev string, lockedTag, wanted, latest *semver.Version) bool {
+	switch {
+	case locked == "":
+		return false
+	case wanted != nil && wantedRev != locked:
+		return true
+	case latest != nil && wanted != nil && latest.GreaterThan(wanted):
+		return true
+	case latest != nil && lockedTag != nil && latest.GreaterThan(lockedTag):
+		return true
+	}
+
+	return false
+}
+
+// newestVersions returns the newest version satisfying the constraint, if
+// there is one, and the newest version that is not a pre-release. The
+// versions must be sorted newest first.
+func newestVersions(semvers []*semver.Version, constraint *semver.Constraints) (wanted, latest *semver.Version) {
+	for _, v := range semvers {
+		if wanted == nil && constraint != nil && constraint.Check(v) {
+			wanted = v
+		}
+		if latest == nil && v.Prerelease() == "" {
+			latest = v
+		}
+	}
+
+	return wanted, latest
+}
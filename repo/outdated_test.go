@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/semver"
+)
+
+func TestNewestVersions(t *testing.T) {
+	semvers := getSemVers([]string{"master", "v1.0.0", "v1.2.0", "1.3.1", "v2.0.0", "v2.1.0-beta.1", "dev"})
+	sort.Sort(sort.Reverse(semver.Collection(semvers)))
+
+	c, err := semver.NewConstraint("^1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted, latest := newestVersions(semvers, c)
+	if wanted == nil || wanted.Original() != "1.3.1" {
+		t.Errorf("Expected wanted version 1.3.1 but got %v", wanted)
+	}
+	if latest == nil || latest.Original() != "v2.0.0" {
+		t.Errorf("Expected latest version v2.0.0 but got %v", latest)
+	}
+
+	wanted, _ = newestVersions(semvers, nil)
+	if wanted != nil {
+		t.Errorf("Expected no wanted version without a constraint but got %s", wanted)
+	}
+
+	wanted, latest = newestVersions(nil, c)
+	if wanted != nil || latest != nil {
+		t.Error("Expected no versions from an empty list")
+	}
+}
+
+func TestIsOutdated(t *testing.T) {
+	v := func(s string) *semver.Version {
+		return semver.MustParse(s)
+	}
+	tests := []struct {
+		name              string
+		locked, wantedRev string
+		lockedTag         *semver.Version
+		wanted, latest    *semver.Version
+		want              bool
+	}{
+		{"current", "r12", "r12", v("v1.2.0"), v("v1.2.0"), v("v1.2.0"), false},
+		{"wanted newer", "r11", "r12", v("v1.1.0"), v("v1.2.0"), v("v1.2.0"), true},
+		{"latest newer", "r12", "r12", v("v1.2.0"), v("v1.2.0"), v("v2.0.0"), true},
+		{"branch", "abc", "", nil, nil, v("v1.0.0"), false},
+		{"tag behind", "r10", "", v("v1.0.0"), nil, v("v1.2.0"), true},
+		{"not locked", "", "r12", nil, v("v1.2.0"), v("v2.0.0"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isOutdated(tt.locked, tt.wantedRev, tt.lockedTag, tt.wanted, tt.latest); got != tt.want {
+			t.Errorf("%s: Expected outdated to be %t but got %t", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestDependencyVersionsLocal(t *testing.T) {
+	deps := []*cfg.Dependency{
+		{Name: "example.com/local", Path: "../local"},
+		{Name: "example.com/archive", Archive: "https://example.com/archive.tgz"},
+	}
+	for _, d := range deps {
+		vi, err := DependencyVersions(d, "abc123")
+		if err != nil {
+			t.Errorf("Unexpected error checking the versions of %s: %s", d.Name, err)
+			continue
+		}
+		if vi.Outdated || vi.Wanted != "" || vi.Latest != "" {
+			t.Errorf("Expected no versions for %s but got %+v", d.Name, vi)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+)
+
+// ApplyOverrides sets the overrides on the imports and test imports of a
+// config. It should be used on a clone of the config as it is written to the
+// lock file rather than the glide.yaml file.
+func ApplyOverrides(conf *cfg.Config) {
+	for _, d := range conf.Imports {
+		applyOverride(conf, d, "")
+	}
+	for _, d := range conf.DevImports {
+		applyOverride(conf, d, "")
+	}
+}
+
+// applyOverride sets the version, repository, VCS type, and path from the override
+// for a dependency, if there is one. Replaced values are logged along with the
+// package that asked for them. An empty from is the glide.yaml file.
+func applyOverride(conf *cfg.Config, dep *cfg.Dependency, from string) {
+	o := conf.Overrides.Get(dep.Name)
+	if o == nil {
+		return
+	}
+	if from == "" {
+		from = "glide.yaml"
+	}
+
+	if o.Reference != "" && dep.Reference != o.Reference {
+		if dep.Reference != "" {
+			msg.Info("--> Overriding %s version %s asked for by %s with %s", dep.Name, dep.Reference, from, o.Reference)
+		}
+		dep.Reference = o.Reference
+		dep.Pin = ""
+	}
+	if o.Repository != "" && dep.Repository != o.Repository {
+		if dep.Repository != "" {
+			msg.Info("--> Overriding %s repo %s asked for by %s with %s", dep.Name, dep.Repository, from, o.Repository)
+		}
+		dep.Repository = o.Repository
+		dep.Pin = ""
+	}
+	if o.VcsType != "" && dep.VcsType != o.VcsType {
+		if dep.VcsType != "" {
+			msg.Info("--> Overriding %s vcs %s asked for by %s with %s", dep.Name, dep.VcsType, from, o.VcsType)
+		}
+		dep.VcsType = o.VcsType
+	}
+	if o.Path != "" && dep.Path != o.Path {
+		if dep.Path != "" {
+			msg.Info("--> Overriding %s path %s asked for by %s with %s", dep.Name, dep.Path, from, o.Path)
+		}
+		dep.Path = o.Path
+		dep.Pin = ""
+	}
+}
@@ -0,0 +1,321 @@
+package repo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cp "github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/glide/proxy"
+	"github.com/Masterminds/semver"
+)
+
+// proxyMarker is the file in the cached copy of a snapshot from a proxy
+// recording the proxy and the revision of the snapshot, followed by the
+// version the proxy reported and the reference it was fetched for.
+const proxyMarker = ".glide-proxy"
+
+// proxyFetcher is a Fetcher for dependencies served by a proxy. The cache
+// holds a snapshot of the files at the revision being used rather than a
+// repository. When the proxy does not have a package its repository is used
+// instead.
+type proxyFetcher struct {
+	base string
+	vcs  *vcsFetcher
+}
+
+// Get downloads a snapshot of a dependency from the proxy.
+func (f *proxyFetcher) Get(dep *cfg.Dependency, dest string) error {
+	if _, err := os.Stat(dest); err == nil && !isSnapshot(dest) {
+		return f.vcs.Get(dep, dest)
+	}
+
+	if _, err := syncSnapshot(f.base, dep, dest); err != nil {
+		return f.fallback(dep, dest, err)
+	}
+
+	return nil
+}
+
+// Update downloads a new snapshot when the reference of a dependency is at a
+// different revision than the cached one. Copies of repositories in the cache
+// are updated from the repository.
+func (f *proxyFetcher) Update(dep *cfg.Dependency, dest string, force bool) error {
+	if !isSnapshot(dest) {
+		return f.vcs.Update(dep, dest, force)
+	}
+	if cp.Offline {
+		msg.Debug("Offline mode. Using the cached copy of %s without fetching updates", dep.Name)
+		return nil
+	}
+
+	if _, err := syncSnapshot(f.base, dep, dest); err != nil {
+		return f.fallback(dep, dest, err)
+	}
+
+	return nil
+}
+
+// Export copies a snapshot or repository.
+func (f *proxyFetcher) Export(dep *cfg.Dependency, src, dest string) error {
+	if !isSnapshot(src) {
+		return f.vcs.Export(dep, src, dest)
+	}
+
+	return exportUnpacked(src, dest, proxyMarker)
+}
+
+// fallback replaces a snapshot with the repository of a dependency after the
+// proxy failed.
+func (f *proxyFetcher) fallback(dep *cfg.Dependency, dest string, err error) error {
+	if err == proxy.ErrNotFound {
+		msg.Debug("%s is not on the proxy %s. Fetching it from %s", dep.Name, f.base, dep.Remote())
+	} else {
+		msg.Warn("Unable to fetch %s from the proxy %s: %s. Fetching it from %s", dep.Name, f.base, err, dep.Remote())
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return err
+	}
+
+	return f.vcs.Get(dep, dest)
+}
+
+// isSnapshot returns true when dir holds a snapshot from a proxy.
+func isSnapshot(dir string) bool {
+	_, _, err := readSnapshotMarker(dir)
+	return err == nil
+}
+
+func readSnapshotMarker(dir string) (string, string, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, proxyMarker))
+	if err != nil {
+		return "", "", err
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) < 2 || len(lines) > 4 {
+		return "", "", fmt.Errorf("Invalid proxy information in %s", dir)
+	}
+
+	return lines[0], lines[1], nil
+}
+
+// readSnapshotRefs returns the version reported by the proxy and the
+// reference a snapshot was fetched for. Both are empty for snapshots fetched
+// before they were recorded.
+func readSnapshotRefs(dir string) (string, string) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, proxyMarker))
+	if err != nil {
+		return "", ""
+	}
+	lines := append(strings.Split(string(b), "\n"), "", "", "", "")
+
+	return lines[2], lines[3]
+}
+
+func snapshotMarker(base string, info *proxy.Info, ref string) string {
+	return base + "\n" + info.Revision + "\n" + info.Version + "\n" + ref + "\n"
+}
+
+// syncSnapshot makes the snapshot at dest match the reference of a dependency
+// and returns the revision of it.
+func syncSnapshot(base string, dep *cfg.Dependency, dest string) (string, error) {
+	info, err := proxyResolve(base, dep)
+	if err != nil {
+		return "", err
+	}
+
+	if _, rev, err := readSnapshotMarker(dest); err == nil && rev == info.Revision {
+		msg.Debug("%s is already set to revision %s", dep.Name, rev)
+		err := ioutil.WriteFile(filepath.Join(dest, proxyMarker), []byte(snapshotMarker(base, info, dep.Reference)), 0644)
+		return rev, err
+	}
+
+	u := proxy.ZipURL(base, dep.Name, info.Revision)
+	msg.Debug("Downloading %s for %s", u, dep.Name)
+	file, _, err := download(u)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if err := unpack(file, "zip", dest, proxyMarker, snapshotMarker(base, info, dep.Reference)); err != nil {
+		return "", err
+	}
+
+	return info.Revision, nil
+}
+
+// proxyResolve finds the revision for the reference of a dependency on a
+// proxy. References that are not a version, branch, or commit id known to the
+// proxy are used as semantic version constraints.
+func proxyResolve(base string, dep *cfg.Dependency) (*proxy.Info, error) {
+	ver := dep.Reference
+	if !strings.HasPrefix(ver, "^") {
+		info, err := proxy.GetInfo(base, dep.Name, ver)
+		if err == nil || err != proxy.ErrNotFound || ver == "" {
+			return info, err
+		}
+	}
+
+	constraint, err := semver.NewConstraint(ver)
+	if err != nil {
+		msg.Warn("The reference '%s' is not valid\n", ver)
+		return nil, err
+	}
+	vers, err := proxy.Versions(base, dep.Name)
+	if err != nil {
+		return nil, err
+	}
+	semvers := getSemVers(vers)
+	sort.Sort(sort.Reverse(semver.Collection(semvers)))
+	for _, v := range semvers {
+		if constraint.Check(v) {
+			return proxy.GetInfo(base, dep.Name, v.Original())
+		}
+	}
+
+	return nil, fmt.Errorf("Unable to find a version of %s for the constraint %s on the proxy %s", dep.Name, ver, base)
+}
+
+// snapshotVersion sets the version of a snapshot from a proxy.
+func snapshotVersion(dep *cfg.Dependency, dir string) error {
+	base, rev, err := readSnapshotMarker(dir)
+	if err != nil {
+		return err
+	}
+
+	if cp.Offline {
+		if !snapshotMatches(dep.Reference, rev, dir) {
+			return fmt.Errorf("Offline mode. The cached snapshot of %s is at revision %s rather than %s. Run without --offline to fetch it", dep.Name, rev, dep.Reference)
+		}
+	} else if rev, err = syncSnapshot(base, dep, dir); err != nil {
+		return err
+	}
+	if dep.Reference != "" {
+		msg.With(msg.Fields{"package": dep.Name, "version": dep.Reference, "action": "setting version"}).Info("--> Setting version for %s to %s from the proxy.\n", dep.Name, dep.Reference)
+	}
+	dep.Pin = rev
+
+	return nil
+}
+
+// snapshotMatches returns true when a cached snapshot at a revision is the
+// one for a reference. Without the proxy only the revision, the version and
+// reference recorded when fetching it, and semantic version constraints on
+// the version can be checked. The default branch uses the cached snapshot.
+func snapshotMatches(ref, rev, dir string) bool {
+	ver, fetched := readSnapshotRefs(dir)
+	if ref == "" || ref == fetched || ref == rev || (ver != "" && ref == ver) {
+		return true
+	}
+	if len(ref) >= 7 && strings.HasPrefix(rev, ref) {
+		return true
+	}
+	if sv, err := semver.NewVersion(ver); err == nil {
+		if c, err := semver.NewConstraint(ref); err == nil {
+			return c.Check(sv)
+		}
+	}
+
+	return false
+}
+
+// snapshotIsRange returns true when a reference for a package cached as a
+// snapshot is a semantic version constraint rather than a version, branch or
+// commit id known to the proxy. There is no repository to check it in.
+func snapshotIsRange(name, dir, ref string) bool {
+	if _, err := semver.NewConstraint(ref); err != nil {
+		return false
+	}
+	base, _, err := readSnapshotMarker(dir)
+	if err != nil || cp.Offline || strings.HasPrefix(ref, "^") {
+		return true
+	}
+	_, err = proxy.GetInfo(base, name, ref)
+
+	return err != nil
+}
+
+// snapshotRefs lists the versions of a package cached as a snapshot. The bool
+// is false when dir is not a snapshot.
+func snapshotRefs(name, dir string) ([]string, bool, error) {
+	base, _, err := readSnapshotMarker(dir)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	refs, err := proxy.Versions(base, name)
+	return refs, true, err
+}
+
+// snapshotVersions returns the versions of a package cached as a snapshot and
+// functions checking references and finding their revisions. The proxy is
+// asked when it can be reached. Otherwise only the version and revision
+// recorded in the snapshot are known.
+func snapshotVersions(name, dir string) ([]string, func(string) bool, func(string) string) {
+	base, rev, _ := readSnapshotMarker(dir)
+	if !cp.Offline {
+		refs, _, err := snapshotRefs(name, dir)
+		if err == nil {
+			isReference := func(ref string) bool {
+				_, err := proxy.GetInfo(base, name, ref)
+				return err == nil
+			}
+			commit := func(ref string) string {
+				if i, err := proxy.GetInfo(base, name, ref); err == nil {
+					return i.Revision
+				}
+				return ""
+			}
+			return refs, isReference, commit
+		}
+		msg.Warn("Unable to list the versions of %s on the proxy %s: %s. Using the version of the cached snapshot", name, base, err)
+	}
+
+	ver, fetched := readSnapshotRefs(dir)
+	var refs []string
+	if ver != "" {
+		refs = []string{ver}
+	}
+	recorded := func(ref string) bool {
+		return ref != "" && (ref == ver || ref == fetched || ref == rev)
+	}
+	commit := func(ref string) string {
+		if recorded(ref) {
+			return rev
+		}
+		return ""
+	}
+
+	return refs, recorded, commit
+}
+
+// SnapshotInfo returns the version and revision of a package cached as a
+// snapshot from a proxy for the revision rev. The version recorded in the
+// snapshot is used when it is at rev. Otherwise the proxy is asked. The bool is
+// false when dir is not a snapshot.
+func SnapshotInfo(name, dir, rev string) (*proxy.Info, bool, error) {
+	base, srev, err := readSnapshotMarker(dir)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	if ver, _ := readSnapshotRefs(dir); srev == rev && ver != "" {
+		if _, err := semver.NewVersion(ver); err == nil {
+			return &proxy.Info{Version: ver, Revision: rev}, true, nil
+		}
+	}
+	if cp.Offline {
+		return nil, true, fmt.Errorf("Offline mode. The version of revision %s is not recorded in the cached snapshot of %s", rev, name)
+	}
+
+	info, err := proxy.GetInfo(base, name, rev)
+	return info, true, err
+}
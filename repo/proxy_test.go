@@ -0,0 +1,208 @@
+package repo
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cp "github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/proxy"
+)
+
+func TestProxyFetcher(t *testing.T) {
+	var zb bytes.Buffer
+	zw := zip.NewWriter(&zb)
+	w, err := zw.Create("foo-r11/foo.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("package foo\n"))
+	zw.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/foo/@v/list":
+			w.Write([]byte("v1.0.0\nv1.1.0\nv2.0.0\n"))
+		case "/example.com/foo/@v/v1.1.0.info":
+			w.Write([]byte(`{"Version": "v1.1.0", "Revision": "r11"}`))
+		case "/example.com/foo/@v/r11.zip":
+			w.Write(zb.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	tmp, err := ioutil.TempDir("", "glide-proxy-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	home := gpath.Home()
+	gpath.SetHome(tmp)
+	defer func() {
+		ioutil.WriteFile(filepath.Join(tmp, "proxy.yaml"), []byte(""), 0644)
+		proxy.Load()
+		gpath.SetHome(home)
+	}()
+	if err := ioutil.WriteFile(filepath.Join(tmp, "proxy.yaml"), []byte("url: "+ts.URL+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := proxy.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	dep := &cfg.Dependency{Name: "example.com/foo", Reference: "^1.0.0"}
+	f, ok := fetcherFor(dep).(*proxyFetcher)
+	if !ok {
+		t.Fatal("Expected the proxy to be used for example.com/foo")
+	}
+	if _, ok := fetcherFor(&cfg.Dependency{Name: "example.com/foo", Repository: "https://example.com/foo.git"}).(*vcsFetcher); !ok {
+		t.Error("Expected the repository to be used when one is set")
+	}
+
+	dest := filepath.Join(tmp, "cache")
+	if err := f.Get(dep, dest); err != nil {
+		t.Fatalf("Unable to fetch from the proxy: %s", err)
+	}
+	if !isSnapshot(dest) {
+		t.Fatal("Expected a snapshot in the cache")
+	}
+	if err := snapshotVersion(dep, dest); err != nil || dep.Pin != "r11" {
+		t.Errorf("Expected the revision r11 but got %q (%v)", dep.Pin, err)
+	}
+
+	refs, ok, err := snapshotRefs(dep.Name, dest)
+	if !ok || err != nil || len(refs) != 3 {
+		t.Errorf("Unexpected versions %v (%v)", refs, err)
+	}
+
+	vendor := filepath.Join(tmp, "vendor")
+	if err := f.Export(dep, dest, vendor); err != nil {
+		t.Fatalf("Unable to export snapshot: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(vendor, "foo.go")); err != nil {
+		t.Errorf("Expected foo.go to be exported: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(vendor, proxyMarker)); err == nil {
+		t.Error("Expected the proxy information not to be exported")
+	}
+
+	if _, err := proxyResolve(ts.URL, &cfg.Dependency{Name: "example.com/missing", Reference: "^1.0.0"}); err != proxy.ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a missing package but got %v", err)
+	}
+
+	// Without the proxy the snapshot is only used for the references it
+	// matches.
+	cp.Offline = true
+	defer func() { cp.Offline = false }()
+	for ref, ok := range map[string]bool{"^1.0.0": true, "~1.1.0": true, "v1.1.0": true, "r11": true, "": true, "v2.0.0": false, "master": false} {
+		d := &cfg.Dependency{Name: "example.com/foo", Reference: ref}
+		if err := snapshotVersion(d, dest); (err == nil) != ok {
+			t.Errorf("Unexpected result setting the offline snapshot to %q: %v", ref, err)
+		}
+	}
+}
+
+func TestSnapshotIsRange(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "glide-proxy-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	home := gpath.Home()
+	gpath.SetHome(tmp)
+	defer gpath.SetHome(home)
+	cp.Offline = true
+	defer func() { cp.Offline = false }()
+
+	dep := &cfg.Dependency{Name: "example.com/foo"}
+	key, err := cp.Key(dep.Remote())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(cp.Location(), "src", key)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, proxyMarker), []byte("https://proxy.example.com\nr11\nv1.1.0\n^1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := newVcsSource(&cfg.Config{}, nil, false)
+	for ref, want := range map[string]bool{"master": false, "abc123": false, "^1.2.0": true, ">=1.0.0, <2.0.0": true} {
+		r, err := src.IsRange(dep.Name, ref)
+		if err != nil || r != want {
+			t.Errorf("Expected IsRange(%q) to be %t but got %t (%v)", ref, want, r, err)
+		}
+	}
+
+	root := []Requirement{{Name: dep.Name, Reference: "r11"}}
+	sol, err := Solve(src, root, []string{dep.Name})
+	if err != nil || sol[dep.Name] != "r11" {
+		t.Errorf("Expected r11 for a snapshot but got %v (%v)", sol, err)
+	}
+
+	// Offline the requirements of other references can not be read.
+	root = []Requirement{{Name: dep.Name, Reference: "master"}}
+	if _, err := Solve(src, root, []string{dep.Name}); err == nil {
+		t.Error("Expected an error solving for a reference the offline snapshot is not at")
+	} else if _, ok := err.(*ConflictError); !ok {
+		t.Errorf("Expected a conflict but got %s", err)
+	}
+}
+
+func TestSnapshotVersionsOffline(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "glide-proxy-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	home := gpath.Home()
+	gpath.SetHome(tmp)
+	defer gpath.SetHome(home)
+	cp.Offline = true
+	defer func() { cp.Offline = false }()
+
+	dep := &cfg.Dependency{Name: "example.com/foo", Reference: "^1.0.0"}
+	key, err := cp.Key(dep.Remote())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(cp.Location(), "src", key)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, proxyMarker), []byte("https://proxy.example.com\nr11\nv1.1.0\n^1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vi, err := DependencyVersions(dep, "r11")
+	if err != nil {
+		t.Fatalf("Unexpected error checking the versions of a snapshot: %s", err)
+	}
+	if vi.LockedTag != "v1.1.0" || vi.Wanted != "v1.1.0" || vi.Outdated {
+		t.Errorf("Expected the snapshot to be at v1.1.0 and not outdated but got %+v", vi)
+	}
+
+	info, ok, err := SnapshotInfo(dep.Name, dir, "r11")
+	if !ok || err != nil || info.Version != "v1.1.0" {
+		t.Errorf("Expected the recorded version v1.1.0 but got %v, %t, %v", info, ok, err)
+	}
+	if _, ok, err := SnapshotInfo(dep.Name, dir, "r12"); !ok || err == nil {
+		t.Error("Expected an error for a revision the offline snapshot is not at")
+	}
+	if _, ok, _ := SnapshotInfo(dep.Name, tmp, "r11"); ok {
+		t.Error("Expected a directory without a marker not to be a snapshot")
+	}
+}
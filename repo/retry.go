@@ -0,0 +1,180 @@
+package repo
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/glide/msg"
+)
+
+var (
+	// retryCount is the number of times a failed fetch, update, or listing
+	// of versions is retried when the error looks transient.
+	retryCount = 0
+
+	// retryBackoff is the wait before the first retry. It doubles for each
+	// retry after that up to maxRetryBackoff.
+	retryBackoff = time.Second
+
+	retryLock  sync.Mutex
+	retryStats []retryStat
+)
+
+const maxRetryBackoff = 30 * time.Second
+
+type retryStat struct {
+	name, op string
+	attempts int
+	err      error
+}
+
+// SetRetries sets the number of retries for transient failures and the wait
+// before the first one.
+func SetRetries(count int, backoff time.Duration) {
+	retryCount = count
+	retryBackoff = backoff
+}
+
+// Patterns in the errors and command output of transient failures, such as
+// timeouts and server errors, and of fatal ones, such as failed
+// authentication or missing references. Fatal patterns are checked first.
+var (
+	fatalPatterns = []string{
+		"authentication failed",
+		"permission denied",
+		"could not read username",
+		"could not read password",
+		"repository not found",
+		"does not exist",
+		"not found",
+		"unknown revision",
+		"did not match any",
+		"couldn't find remote ref",
+		"invalid reference",
+		"401",
+		"403",
+		"404",
+		"offline mode",
+		"uncommitted changes",
+		"checksum mismatch",
+	}
+	retryablePatterns = []string{
+		"timeout",
+		"timed out",
+		"connection reset",
+		"connection refused",
+		"connection closed",
+		"broken pipe",
+		"temporary failure",
+		"could not resolve host",
+		"no route to host",
+		"network is unreachable",
+		"early eof",
+		"unexpected eof",
+		"remote end hung up",
+		"rpc failed",
+		"tls handshake",
+		"500 internal server error",
+		"502",
+		"503",
+		"504",
+		"bad gateway",
+		"service unavailable",
+	}
+)
+
+// retryable returns true when an error looks like a transient failure worth
+// retrying.
+func retryable(err error) bool {
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return true
+	}
+
+	text := strings.ToLower(err.Error())
+	if o, ok := err.(interface {
+		Out() string
+	}); ok {
+		text += "\n" + strings.ToLower(o.Out())
+	}
+
+	for _, p := range fatalPatterns {
+		if strings.Contains(text, p) {
+			return false
+		}
+	}
+	for _, p := range retryablePatterns {
+		if strings.Contains(text, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withRetry runs fn and retries it with exponential backoff while it fails
+// with transient errors. cleanup, when not nil, is run before each retry.
+func withRetry(name, op string, cleanup func(), fn func() error) error {
+	wait := retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			if attempt > 1 {
+				msg.Info("--> %s %s succeeded on attempt %d", op, name, attempt)
+				recordRetry(name, op, attempt, nil)
+			}
+			return nil
+		}
+
+		if attempt > retryCount || !retryable(err) {
+			if attempt > 1 {
+				recordRetry(name, op, attempt, err)
+			}
+			return err
+		}
+
+		msg.Warn("%s %s failed on attempt %d of %d: %s. Retrying in %s", op, name, attempt, retryCount+1, err, wait)
+		time.Sleep(wait)
+		if cleanup != nil {
+			cleanup()
+		}
+		wait *= 2
+		if wait > maxRetryBackoff {
+			wait = maxRetryBackoff
+		}
+	}
+}
+
+func recordRetry(name, op string, attempts int, err error) {
+	retryLock.Lock()
+	defer retryLock.Unlock()
+	retryStats = append(retryStats, retryStat{name: name, op: op, attempts: attempts, err: err})
+}
+
+// LogRetrySummary displays the operations that were retried and clears them.
+func LogRetrySummary() {
+	retryLock.Lock()
+	stats := retryStats
+	retryStats = nil
+	retryLock.Unlock()
+
+	if len(stats) == 0 {
+		return
+	}
+
+	failed := 0
+	for _, s := range stats {
+		if s.err != nil {
+			failed++
+		}
+	}
+	msg.Info("Retried %d operations after transient failures. %d succeeded and %d failed:", len(stats), len(stats)-failed, failed)
+	for _, s := range stats {
+		if s.err != nil {
+			msg.Info("--> %s %s failed after %d attempts: %s", s.op, s.name, s.attempts, s.err)
+		} else {
+			msg.Info("--> %s %s succeeded after %d attempts", s.op, s.name, s.attempts)
+		}
+	}
+}
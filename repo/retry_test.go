@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/vcs"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err       error
+		retryable bool
+	}{
+		{vcs.NewRemoteError("Unable to get repository", errors.New("exit status 128"), "fatal: unable to access 'https://example.com/foo/': Could not resolve host: example.com"), true},
+		{vcs.NewRemoteError("Unable to update repository", errors.New("exit status 128"), "error: RPC failed; curl 56 GnuTLS recv error\nfatal: early EOF"), true},
+		{errors.New("Unable to download https://example.com/foo.zip: 503 Service Unavailable"), true},
+		{vcs.NewRemoteError("Unable to get repository", errors.New("exit status 128"), "fatal: Authentication failed for 'https://example.com/foo/'"), false},
+		{vcs.NewRemoteError("Unable to get repository", errors.New("exit status 128"), "remote: Repository not found."), false},
+		{errors.New("Unable to download https://example.com/foo.zip: 404 Not Found"), false},
+		{errors.New("something unexpected"), false},
+	}
+
+	for _, tt := range tests {
+		if r := retryable(tt.err); r != tt.retryable {
+			t.Errorf("Expected retryable %t but got %t for %v", tt.retryable, r, tt.err)
+		}
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	SetRetries(3, time.Millisecond)
+	defer SetRetries(0, time.Second)
+	defer LogRetrySummary()
+
+	attempts, cleanups := 0, 0
+	err := withRetry("example.com/foo", "Fetching", func() { cleanups++ }, func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("connection reset by peer")
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 || cleanups != 2 {
+		t.Errorf("Expected success on the third attempt but got %v after %d attempts and %d cleanups", err, attempts, cleanups)
+	}
+
+	attempts = 0
+	err = withRetry("example.com/foo", "Fetching", nil, func() error {
+		attempts++
+		return errors.New("Authentication failed")
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("Expected a fatal error not to be retried but got %d attempts", attempts)
+	}
+
+	attempts = 0
+	err = withRetry("example.com/foo", "Fetching", nil, func() error {
+		attempts++
+		return errors.New("operation timed out")
+	})
+	if err == nil || attempts != 4 {
+		t.Errorf("Expected 4 attempts before giving up but got %d", attempts)
+	}
+
+	if len(retryStats) != 2 || retryStats[0].err != nil || retryStats[1].err == nil {
+		t.Errorf("Unexpected retry summary %+v", retryStats)
+	}
+}
@@ -21,15 +21,31 @@ func getSemVers(refs []string) []*semver.Version {
 
 // Get all the references for a repo. This includes the tags and branches.
 func getAllVcsRefs(repo vcs.Repo) ([]string, error) {
-	tags, err := repo.Tags()
-	if err != nil {
-		return []string{}, err
-	}
+	var refs []string
+	err := withRetry(repo.Remote(), "Listing versions of", nil, func() error {
+		// Shallow clones start without tags.
+		if isShallow(repo) {
+			if err := fetchShallowTags(repo); err != nil {
+				return err
+			}
+		}
+
+		tags, err := repo.Tags()
+		if err != nil {
+			return err
+		}
+
+		branches, err := repo.Branches()
+		if err != nil {
+			return err
+		}
 
-	branches, err := repo.Branches()
+		refs = append(branches, tags...)
+		return nil
+	})
 	if err != nil {
 		return []string{}, err
 	}
 
-	return append(branches, tags...), nil
+	return refs, nil
 }
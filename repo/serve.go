@@ -0,0 +1,175 @@
+package repo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cp "github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/glide/proxy"
+	v "github.com/Masterminds/vcs"
+)
+
+// CacheHandler serves the repositories in the cache using the proxy protocol
+// so other instances of Glide can use them as their proxy. Only what is
+// already in the cache is served. Nothing is fetched.
+func CacheHandler() http.Handler {
+	return http.HandlerFunc(serveCache)
+}
+
+func serveCache(w http.ResponseWriter, r *http.Request) {
+	msg.Debug("%s %s", r.Method, r.URL.Path)
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, err := proxy.ParsePath(r.URL.Path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	dep := &cfg.Dependency{Name: req.Name}
+	key, err := cp.Key(dep.Remote())
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := cp.Lock(key); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer cp.Unlock(key)
+
+	dir := filepath.Join(cp.Location(), "src", key)
+	if _, err := os.Stat(dir); err != nil || isSnapshot(dir) {
+		http.NotFound(w, r)
+		return
+	}
+	repo, err := dep.GetRepo(dir)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch req.Kind {
+	case proxy.ListRequest:
+		refs, err := getAllVcsRefs(repo)
+		if err != nil {
+			serveError(w, req, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(strings.Join(refs, "\n") + "\n"))
+	case proxy.InfoRequest, proxy.LatestRequest:
+		ref := req.Ref
+		if req.Kind == proxy.LatestRequest {
+			if ref = cachedDefaultBranch(key, repo); ref == "" {
+				http.NotFound(w, r)
+				return
+			}
+		}
+		ci, err := repo.CommitInfo(branchRef(repo, ref))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&proxy.Info{Version: ref, Revision: ci.Commit, Time: ci.Date.UTC()})
+	case proxy.ZipRequest:
+		if _, err := repo.CommitInfo(req.Ref); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		b, err := archiveRevision(repo, path.Base(req.Name)+"-"+req.Ref, req.Ref)
+		if err == errNoArchive {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		} else if err != nil {
+			serveError(w, req, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		http.ServeContent(w, r, req.Ref+".zip", time.Time{}, bytes.NewReader(b))
+	}
+}
+
+func serveError(w http.ResponseWriter, req *proxy.Request, err error) {
+	msg.Warn("Unable to serve %s for %s: %s", req.Kind, req.Name, err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// cachedDefaultBranch returns the default branch of a repository in the cache
+// without looking it up remotely.
+func cachedDefaultBranch(key string, repo v.Repo) string {
+	if d, err := cp.RepoData(key); err == nil && d.DefaultBranch != "" {
+		return d.DefaultBranch
+	}
+	if repo.Vcs() == v.Git {
+		out, err := repo.RunFromDir("git", "symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+		if err == nil {
+			return strings.TrimPrefix(strings.TrimSpace(string(out)), "origin/")
+		}
+	}
+
+	return ""
+}
+
+// branchRef returns the reference to read a branch from. The local branches
+// of Git repositories in the cache may be behind the fetched ones.
+func branchRef(repo v.Repo, ref string) string {
+	if repo.Vcs() != v.Git {
+		return ref
+	}
+	if ib, err := isBranch(ref, repo); err == nil && ib {
+		return "origin/" + ref
+	}
+
+	return ref
+}
+
+// errNoArchive is returned for repositories that snapshots can not be made
+// from.
+var errNoArchive = errors.New("Snapshots are only available for Git and Mercurial repositories")
+
+// archiveRevision returns a zip archive of the files in a repository at a
+// revision with all the files in the directory prefix.
+func archiveRevision(repo v.Repo, prefix, rev string) ([]byte, error) {
+	switch repo.Vcs() {
+	case v.Git:
+		cmd := exec.Command("git", "archive", "--format=zip", "--prefix="+prefix+"/", rev)
+		cmd.Dir = repo.LocalPath()
+		cmd.Env = envForDir(cmd.Dir)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, v.NewLocalError("Unable to archive revision", err, stderr.String())
+		}
+		return out, nil
+	case v.Hg:
+		tmp, err := ioutil.TempDir("", "glide-serve")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp)
+		f := filepath.Join(tmp, "archive.zip")
+		if out, err := repo.RunFromDir("hg", "archive", "-t", "zip", "-r", rev, "-p", prefix, f); err != nil {
+			return nil, v.NewLocalError("Unable to archive revision", err, string(out))
+		}
+		return ioutil.ReadFile(f)
+	}
+
+	return nil, errNoArchive
+}
@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	gpath "github.com/Masterminds/glide/path"
+)
+
+func TestCacheHandler(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	tmp, err := ioutil.TempDir("", "glide-serve-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	home := gpath.Home()
+	gpath.SetHome(tmp)
+	defer gpath.SetHome(home)
+
+	key, err := cache.Key("https://example.com/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(cache.Location(), "src", key)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "foo.go"), []byte("package foo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"remote", "add", "origin", "https://example.com/foo"},
+		{"add", "foo.go"},
+		{"-c", "user.name=glide", "-c", "user.email=glide@example.com", "commit", "-q", "-m", "Initial"},
+		{"tag", "v1.0.0"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %s %s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	ts := httptest.NewServer(CacheHandler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/example.com/foo/@v/list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(b), "v1.0.0") {
+		t.Errorf("Expected v1.0.0 in the versions but got %q", b)
+	}
+
+	resp, err = http.Get(ts.URL + "/example.com/missing/@v/list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a 404 for a package not in the cache but got %d", resp.StatusCode)
+	}
+
+	// Fetch a snapshot from the served cache like another instance would.
+	dep := &cfg.Dependency{Name: "example.com/foo", Reference: "^1.0.0"}
+	dest := filepath.Join(tmp, "snapshot")
+	rev, err := syncSnapshot(ts.URL, dep, dest)
+	if err != nil {
+		t.Fatalf("Unable to fetch a snapshot from the served cache: %s", err)
+	}
+	if len(rev) != 40 {
+		t.Errorf("Expected a commit id but got %s", rev)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "foo.go")); err != nil {
+		t.Errorf("Expected foo.go in the snapshot: %s", err)
+	}
+}
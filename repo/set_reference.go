@@ -1,12 +1,9 @@
 package repo
 
 import (
-	"sync"
-
 	"github.com/Masterminds/glide/cache"
 	"github.com/Masterminds/glide/cfg"
 	"github.com/Masterminds/glide/msg"
-	"github.com/codegangsta/cli"
 )
 
 // SetReference is a command to set the VCS reference (commit id, tag, etc) for
@@ -18,74 +15,32 @@ func SetReference(conf *cfg.Config, resolveTest bool) error {
 		return nil
 	}
 
-	done := make(chan struct{}, concurrentWorkers)
-	in := make(chan *cfg.Dependency, concurrentWorkers)
-	var wg sync.WaitGroup
-	var lock sync.Mutex
-	var returnErr error
-
-	for i := 0; i < concurrentWorkers; i++ {
-		go func(ch <-chan *cfg.Dependency) {
-			for {
-				select {
-				case dep := <-ch:
-
-					var loc string
-					if dep.Repository != "" {
-						loc = dep.Repository
-					} else {
-						loc = "https://" + dep.Name
-					}
-					key, err := cache.Key(loc)
-					if err != nil {
-						msg.Die(err.Error())
-					}
-					cache.Lock(key)
-					if err := VcsVersion(dep); err != nil {
-						msg.Err("Failed to set version on %s to %s: %s\n", dep.Name, dep.Reference, err)
-
-						// Capture the error while making sure the concurrent
-						// operations don't step on each other.
-						lock.Lock()
-						if returnErr == nil {
-							returnErr = err
-						} else {
-							returnErr = cli.NewMultiError(returnErr, err)
-						}
-						lock.Unlock()
-					}
-					cache.Unlock(key)
-					wg.Done()
-				case <-done:
-					return
-				}
-			}
-		}(in)
-	}
-
+	var deps []*cfg.Dependency
 	for _, dep := range conf.Imports {
 		if !conf.HasIgnore(dep.Name) {
-			wg.Add(1)
-			in <- dep
+			deps = append(deps, dep)
 		}
 	}
-
 	if resolveTest {
 		for _, dep := range conf.DevImports {
 			if !conf.HasIgnore(dep.Name) {
-				wg.Add(1)
-				in <- dep
+				deps = append(deps, dep)
 			}
 		}
 	}
 
-	wg.Wait()
-	// Close goroutines setting the version
-	for i := 0; i < concurrentWorkers; i++ {
-		done <- struct{}{}
-	}
-	// close(done)
-	// close(in)
-
-	return returnErr
+	return concurrently(deps, func(dep *cfg.Dependency) error {
+		key, err := cache.Key(dep.Remote())
+		if err == nil {
+			err = cache.Lock(key)
+		}
+		if err == nil {
+			err = VcsVersion(dep)
+			cache.Unlock(key)
+		}
+		if err != nil {
+			msg.Err("Failed to set version on %s to %s: %s\n", dep.Name, dep.Reference, err)
+		}
+		return err
+	})
 }
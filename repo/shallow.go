@@ -0,0 +1,158 @@
+package repo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	cp "github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	v "github.com/Masterminds/vcs"
+)
+
+var (
+	// shallow is whether Git repositories are cloned shallowly into the
+	// cache by default.
+	shallow bool
+
+	shallowLock sync.Mutex
+
+	// shallowDeps holds the shallow setting of the dependencies in the
+	// glide.yaml file. Dependencies from the lock file do not have it.
+	shallowDeps = make(map[string]bool)
+
+	// tagsFetched records the shallow clones whose tags were fetched in this
+	// run.
+	tagsFetched = make(map[string]bool)
+)
+
+// SetShallow sets whether Git repositories are cloned shallowly into the cache
+// by default.
+func SetShallow(on bool) {
+	shallow = on
+}
+
+// SetShallowConfig records the shallow settings of the dependencies in a
+// glide.yaml file so they apply to the same dependencies from a lock file.
+func SetShallowConfig(conf *cfg.Config) {
+	shallowLock.Lock()
+	defer shallowLock.Unlock()
+
+	for _, deps := range []cfg.Dependencies{conf.Imports, conf.DevImports, conf.Overrides} {
+		for _, d := range deps {
+			if d.Shallow != nil {
+				shallowDeps[d.Name] = *d.Shallow
+			}
+		}
+	}
+}
+
+// useShallow returns true when a dependency is to be cloned shallowly.
+func useShallow(dep *cfg.Dependency) bool {
+	if dep.Shallow != nil {
+		return *dep.Shallow
+	}
+
+	shallowLock.Lock()
+	defer shallowLock.Unlock()
+	if s, ok := shallowDeps[dep.Name]; ok {
+		return s
+	}
+
+	return shallow
+}
+
+// isShallow returns true for shallow Git clones.
+func isShallow(repo v.Repo) bool {
+	if repo.Vcs() != v.Git {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(repo.LocalPath(), ".git", "shallow"))
+	return err == nil
+}
+
+// shallowClone clones the tips of the branches of a Git repository without
+// their history or tags.
+func shallowClone(repo v.Repo) error {
+	if err := os.MkdirAll(filepath.Dir(repo.LocalPath()), 0755); err != nil {
+		return err
+	}
+
+	msg.Debug("Cloning %s shallowly", repo.Remote())
+	cmd := exec.Command("git", "clone", "--depth", "1", "--no-single-branch", "--no-tags", "--recursive", repo.Remote(), repo.LocalPath())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return v.NewRemoteError("Unable to get repository", err, string(out))
+	}
+
+	return nil
+}
+
+// shallowUpdate fetches the new tips of the branches of a shallow clone and
+// moves the checked out branch, if there is one, to its new tip.
+func shallowUpdate(repo v.Repo) error {
+	if out, err := repo.RunFromDir("git", "fetch", "--depth", "1", "origin"); err != nil {
+		return v.NewRemoteError("Unable to update repository", err, string(out))
+	}
+
+	out, err := repo.RunFromDir("git", "symbolic-ref", "--short", "-q", "HEAD")
+	branch := strings.TrimSpace(string(out))
+	if err != nil || branch == "" {
+		// Detached at a tag or commit id.
+		return nil
+	}
+
+	// The history between the old and new tips is not there to merge so the
+	// branch is reset. Uncommitted changes were already checked for.
+	if out, err := repo.RunFromDir("git", "reset", "-q", "--hard", "origin/"+branch); err != nil {
+		return v.NewLocalError("Unable to update repository", err, string(out))
+	}
+
+	return nil
+}
+
+// fetchShallowTags fetches the tags of a shallow clone, without their history,
+// once per run.
+func fetchShallowTags(repo v.Repo) error {
+	shallowLock.Lock()
+	done := tagsFetched[repo.LocalPath()]
+	shallowLock.Unlock()
+	if done || cp.Offline {
+		return nil
+	}
+
+	msg.Info("--> Fetching the tags of the shallow clone of %s", repo.Remote())
+	if out, err := repo.RunFromDir("git", "fetch", "--depth", "1", "origin", "+refs/tags/*:refs/tags/*"); err != nil {
+		return v.NewRemoteError("Unable to fetch tags", err, string(out))
+	}
+
+	shallowLock.Lock()
+	tagsFetched[repo.LocalPath()] = true
+	shallowLock.Unlock()
+	return nil
+}
+
+// deepen fetches a reference missing from a shallow clone. Tags are fetched
+// first, then the reference alone, and when that fails the full history.
+func deepen(repo v.Repo, ref string) error {
+	if err := fetchShallowTags(repo); err != nil {
+		return err
+	}
+	if repo.IsReference(ref) || cp.Offline {
+		return nil
+	}
+
+	msg.Info("--> Fetching %s into the shallow clone of %s", ref, repo.Remote())
+	if _, err := repo.RunFromDir("git", "fetch", "--depth", "1", "origin", ref); err == nil && repo.IsReference(ref) {
+		return nil
+	}
+
+	msg.Info("--> Fetching the full history of %s to find %s", repo.Remote(), ref)
+	if out, err := repo.RunFromDir("git", "fetch", "--unshallow", "--tags", "origin"); err != nil {
+		return v.NewRemoteError("Unable to fetch the history", err, string(out))
+	}
+
+	return nil
+}
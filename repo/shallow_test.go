@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+func TestUseShallow(t *testing.T) {
+	defer SetShallow(false)
+	no := false
+
+	SetShallow(true)
+	if !useShallow(&cfg.Dependency{Name: "example.com/a"}) {
+		t.Error("Expected the --shallow setting to be used")
+	}
+	if useShallow(&cfg.Dependency{Name: "example.com/a", Shallow: &no}) {
+		t.Error("Expected the dependency to opt out of shallow clones")
+	}
+
+	SetShallowConfig(&cfg.Config{Imports: cfg.Dependencies{{Name: "example.com/b", Shallow: &no}}})
+	defer delete(shallowDeps, "example.com/b")
+	if useShallow(&cfg.Dependency{Name: "example.com/b"}) {
+		t.Error("Expected the glide.yaml setting to apply to a dependency from the lock file")
+	}
+}
+
+func TestShallowClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	tmp, err := ioutil.TempDir("", "glide-shallow-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	git := func(dir string, args ...string) string {
+		args = append([]string{"-c", "user.name=glide", "-c", "user.email=glide@example.com"}, args...)
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %s %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	up := filepath.Join(tmp, "upstream")
+	os.MkdirAll(up, 0755)
+	git(up, "init", "-q")
+	var first string
+	for _, tag := range []string{"v1.0.0", "v1.1.0", "v2.0.0"} {
+		ioutil.WriteFile(filepath.Join(up, "foo.go"), []byte("package foo // "+tag+"\n"), 0644)
+		git(up, "add", "foo.go")
+		git(up, "commit", "-q", "-m", tag)
+		git(up, "tag", tag)
+		if first == "" {
+			first = git(up, "rev-parse", "HEAD")
+		}
+	}
+	git(up, "commit", "-q", "--allow-empty", "-m", "After the tags")
+
+	dep := &cfg.Dependency{Name: "example.com/foo", Repository: "file://" + up, VcsType: "git"}
+	repo, err := dep.GetRepo(filepath.Join(tmp, "cache", "foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shallowClone(repo); err != nil {
+		t.Fatalf("Unable to clone shallowly: %s", err)
+	}
+	if !isShallow(repo) {
+		t.Fatal("Expected a shallow clone")
+	}
+	if repo.IsReference("v1.0.0") {
+		t.Error("Expected the tags not to be cloned")
+	}
+
+	refs, err := getAllVcsRefs(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(strings.Join(refs, " "), "v1.1.0") {
+		t.Errorf("Expected the tags to be fetched for the versions but got %v", refs)
+	}
+	if !isShallow(repo) {
+		t.Error("Expected fetching the tags to keep the clone shallow")
+	}
+
+	if err := deepen(repo, first); err != nil {
+		t.Fatalf("Unable to deepen: %s", err)
+	}
+	if !repo.IsReference(first) {
+		t.Errorf("Expected the commit %s to be fetched", first)
+	}
+
+	ioutil.WriteFile(filepath.Join(up, "bar.go"), []byte("package foo\n"), 0644)
+	git(up, "add", "bar.go")
+	git(up, "commit", "-q", "-m", "New commit")
+	if err := shallowUpdate(repo); err != nil {
+		t.Fatalf("Unable to update the shallow clone: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo.LocalPath(), "bar.go")); err != nil {
+		t.Error("Expected the checked out branch to be updated")
+	}
+}
@@ -0,0 +1,845 @@
+package repo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/importer"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/semver"
+	"github.com/Masterminds/vcs"
+)
+
+// Requirement is a version of a package asked for by the project or one of
+// its dependencies.
+type Requirement struct {
+	Name      string
+	Reference string
+
+	// From is the package asking for the version and FromVersion the version
+	// of it the requirement was read from. From is empty for the glide.yaml
+	// file of the project.
+	From        string
+	FromVersion string
+
+	// Override is true for an override in the glide.yaml file of the project.
+	// It replaces all of the other requirements on the package.
+	Override bool
+
+	// Locked is true for the version in the glide.lock file of a package that
+	// is not being updated. It is used over the other exact references.
+	Locked bool
+
+	// Hint is true for a version in the glide.lock file of a dependency. It
+	// is tried before the other versions satisfying the requirements but does
+	// not rule any of them out.
+	Hint bool
+}
+
+func (r Requirement) String() string {
+	from := "glide.yaml"
+	if r.Locked {
+		from = "glide.lock"
+	} else if r.Override {
+		from = "overrides in glide.yaml"
+	} else if r.From != "" {
+		from = r.From
+		if r.FromVersion != "" {
+			from += " " + r.FromVersion
+		}
+		if r.Hint {
+			from += " glide.lock"
+		}
+	}
+
+	return fmt.Sprintf("%s (required by %s)", r.Reference, from)
+}
+
+// VersionSource provides the versions of packages and their requirements to
+// Solve.
+type VersionSource interface {
+
+	// Versions returns the semantic version tags of a package.
+	Versions(name string) ([]*semver.Version, error)
+
+	// IsRange returns true when a reference for a package is a semantic
+	// version constraint rather than a branch, tag or commit id.
+	IsRange(name, ref string) (bool, error)
+
+	// Requirements returns the requirements of a package at a version. The
+	// version is empty for the default branch.
+	Requirements(name, version string) ([]Requirement, error)
+}
+
+// ConflictError is returned by Solve when no versions satisfy all of the
+// requirements. Requirements is a minimal set of the requirements on Name
+// that can not be satisfied together.
+type ConflictError struct {
+	Name         string
+	Requirements []Requirement
+}
+
+func (e *ConflictError) Error() string {
+	lines := []string{fmt.Sprintf("Unable to find a version of %s satisfying all of:", e.Name)}
+	for _, r := range e.Requirements {
+		lines = append(lines, "  "+r.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// maxSolveSteps limits the number of versions tried before Solve gives up.
+const maxSolveSteps = 10000
+
+// Solve chooses a version for each of the packages that satisfies every
+// requirement of the project and of the versions chosen for the packages.
+//
+// The packages with requirements on them are solved one at a time, trying the
+// semantic version tags satisfying the requirements newest first. When a
+// choice leaves a package with no version satisfying its requirements the
+// search backtracks to the previous choice. A package without requirements on
+// it uses the default branch, which is returned as an empty version.
+//
+// Requirements for a branch or commit id can not be compared with semantic
+// version constraints. The one in the glide.yaml file of the project is used
+// over the others, as is a range in it, and a warning is displayed for the
+// requirements that could not be checked. Different ones from dependencies
+// use the first found. An override replaces all of the other requirements on a
+// package.
+//
+// A version whose requirements can not be read is skipped with a warning.
+//
+// Hints are the versions locked by dependencies. They are tried first when
+// they satisfy the other requirements on a package, and a package with only
+// hints on it uses them before the default branch.
+//
+// When no versions satisfy all of the requirements a *ConflictError explains
+// the first conflict found.
+func Solve(src VersionSource, root []Requirement, packages []string) (map[string]string, error) {
+	s := &solver{
+		src:      src,
+		root:     root,
+		packages: make(map[string]bool, len(packages)),
+		versions: make(map[string][]*semver.Version),
+		reqs:     make(map[string][]Requirement),
+		unusable: make(map[string]bool),
+	}
+	for _, p := range packages {
+		s.packages[p] = true
+	}
+
+	// The packages required by the project are solved first.
+	seen := map[string]bool{}
+	for _, r := range root {
+		if s.packages[r.Name] && !seen[r.Name] {
+			seen[r.Name] = true
+			s.order = append(s.order, r.Name)
+		}
+	}
+	rest := []string{}
+	for _, p := range packages {
+		if !seen[p] {
+			seen[p] = true
+			rest = append(rest, p)
+		}
+	}
+	sort.Strings(rest)
+	s.order = append(s.order, rest...)
+
+	if !s.solve() {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, s.conflict
+	}
+
+	sol := make(map[string]string, len(packages))
+	for _, p := range packages {
+		sol[p] = ""
+	}
+	for _, d := range s.decisions {
+		sol[d.name] = d.version
+	}
+	s.warnUnchecked(sol)
+
+	return sol, nil
+}
+
+type decision struct {
+	name, version string
+}
+
+type solver struct {
+	src      VersionSource
+	root     []Requirement
+	packages map[string]bool
+	order    []string
+
+	decisions []decision
+	steps     int
+	conflict  *ConflictError
+	err       error
+
+	versions map[string][]*semver.Version
+	reqs     map[string][]Requirement
+	unusable map[string]bool
+}
+
+func (s *solver) solve() bool {
+	reqs, err := s.requirements()
+	if err != nil {
+		s.err = err
+		return false
+	}
+
+	// The requirements of the last choice may rule out an earlier one.
+	for _, d := range s.decisions {
+		ok, err := s.satisfies(d.name, d.version, reqs)
+		if err != nil {
+			s.err = err
+			return false
+		}
+		if !ok {
+			s.fail(d.name, reqs)
+			return false
+		}
+	}
+
+	name := s.next(reqs)
+	if name == "" {
+		return true
+	}
+
+	cands, err := s.candidates(name, requirementsOn(name, reqs))
+	if err != nil {
+		s.err = err
+		return false
+	}
+	if len(cands) == 0 {
+		s.fail(name, reqs)
+		return false
+	}
+
+	tried := false
+	for _, c := range cands {
+		s.steps++
+		if s.steps > maxSolveSteps {
+			s.err = fmt.Errorf("Unable to find versions satisfying all of the requirements after trying %d versions", maxSolveSteps)
+			return false
+		}
+
+		if s.skip(name, c) {
+			continue
+		}
+
+		msg.Debug("Solver trying %s %s", name, c)
+		tried = true
+		s.decisions = append(s.decisions, decision{name: name, version: c})
+		if s.solve() {
+			return true
+		}
+		s.decisions = s.decisions[:len(s.decisions)-1]
+		if s.err != nil {
+			return false
+		}
+	}
+	if !tried {
+		s.fail(name, reqs)
+	}
+
+	return false
+}
+
+// requirements returns the requirements of the project and of the chosen
+// versions. A package that has not been chosen and has no requirements on it
+// uses the default branch, so its requirements there are included as well.
+func (s *solver) requirements() ([]Requirement, error) {
+	overridden := map[string]bool{}
+	for _, r := range s.root {
+		if r.Override {
+			overridden[r.Name] = true
+		}
+	}
+
+	var reqs []Requirement
+	add := func(rs []Requirement) {
+		for _, r := range rs {
+			if r.Reference != "" && s.packages[r.Name] && (r.Override || r.Locked || !overridden[r.Name]) {
+				reqs = append(reqs, r)
+			}
+		}
+	}
+
+	add(s.root)
+	chosen := map[string]bool{}
+	for _, d := range s.decisions {
+		chosen[d.name] = true
+		rs, err := s.requirementsOf(d.name, d.version)
+		if err != nil {
+			return nil, err
+		}
+		add(rs)
+	}
+
+	constrained := map[string]bool{}
+	for _, r := range reqs {
+		constrained[r.Name] = true
+	}
+	for _, p := range s.order {
+		if !chosen[p] && !constrained[p] {
+			rs, err := s.requirementsOf(p, "")
+			if err != nil {
+				return nil, err
+			}
+			add(rs)
+		}
+	}
+
+	return reqs, nil
+}
+
+func (s *solver) requirementsOf(name, version string) ([]Requirement, error) {
+	k := name + "@" + version
+	if rs, ok := s.reqs[k]; ok {
+		return rs, nil
+	}
+
+	rs, err := s.src.Requirements(name, version)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rs {
+		rs[i].From = name
+		rs[i].FromVersion = version
+	}
+	s.reqs[k] = rs
+
+	return rs, nil
+}
+
+// skip returns true when the requirements of a version of a package can not
+// be read. The version can not be used.
+func (s *solver) skip(name, version string) bool {
+	k := name + "@" + version
+	if s.unusable[k] {
+		return true
+	}
+	if _, err := s.requirementsOf(name, version); err != nil {
+		msg.Warn("Skipping %s %s. Unable to read its requirements: %s", name, version, err)
+		s.unusable[k] = true
+		return true
+	}
+
+	return false
+}
+
+// next returns the first package with requirements on it that has not been
+// chosen yet.
+func (s *solver) next(reqs []Requirement) string {
+	chosen := map[string]bool{}
+	for _, d := range s.decisions {
+		chosen[d.name] = true
+	}
+	constrained := map[string]bool{}
+	for _, r := range reqs {
+		constrained[r.Name] = true
+	}
+	for _, p := range s.order {
+		if !chosen[p] && constrained[p] {
+			return p
+		}
+	}
+
+	return ""
+}
+
+func (s *solver) satisfies(name, version string, reqs []Requirement) (bool, error) {
+	on := requirementsOn(name, reqs)
+	if len(on) == 0 {
+		return true, nil
+	}
+	cands, err := s.candidates(name, on)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range cands {
+		if c == version {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// fail records the conflict on a package when it is the first one found. The
+// requirements are reduced to a minimal set that still conflicts.
+func (s *solver) fail(name string, reqs []Requirement) {
+	if s.conflict != nil {
+		return
+	}
+
+	core := requirementsOn(name, reqs)
+	for i := 0; i < len(core); {
+		trial := append(append([]Requirement{}, core[:i]...), core[i+1:]...)
+		cands, err := s.candidates(name, trial)
+		if err == nil && len(cands) == 0 {
+			core = trial
+		} else {
+			i++
+		}
+	}
+
+	s.conflict = &ConflictError{Name: name, Requirements: core}
+}
+
+// candidates returns the versions of a package satisfying the requirements on
+// it, newest first.
+func (s *solver) candidates(name string, reqs []Requirement) ([]string, error) {
+	var refs, ranges, hints []Requirement
+	for _, r := range reqs {
+		if r.Hint {
+			hints = append(hints, r)
+			continue
+		}
+		if r.Locked {
+			refs = append(refs, r)
+			continue
+		}
+		isRange, err := s.src.IsRange(name, r.Reference)
+		if err != nil {
+			return nil, err
+		}
+		if isRange {
+			ranges = append(ranges, r)
+		} else {
+			refs = append(refs, r)
+		}
+	}
+
+	// A range in the glide.yaml file of the project is kept over the
+	// branches, tags and commit ids dependencies ask for. They are displayed
+	// as warnings once the versions are solved.
+	for _, r := range ranges {
+		if r.From == "" {
+			var kept []Requirement
+			for _, ref := range refs {
+				if ref.From == "" || ref.Locked {
+					kept = append(kept, ref)
+				}
+			}
+			refs = kept
+			break
+		}
+	}
+
+	cons := make([]*semver.Constraints, 0, len(ranges))
+	for _, r := range ranges {
+		c, err := semver.NewConstraint(r.Reference)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid version %s for %s: %s", r, name, err)
+		}
+		cons = append(cons, c)
+	}
+	check := func(v *semver.Version) bool {
+		for _, c := range cons {
+			if !c.Check(v) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if len(refs) > 0 {
+		ref, ok := exactReference(refs)
+		if !ok {
+			return nil, nil
+		}
+
+		// A semantic version tag can be checked against the constraints.
+		// Other references are used as they are.
+		if v, err := semver.NewVersion(ref); err == nil && !check(v) {
+			return nil, nil
+		}
+		return []string{ref}, nil
+	}
+
+	if len(ranges) == 0 {
+		var cands []string
+		for _, h := range hints {
+			cands = appendUnique(cands, h.Reference)
+		}
+		return append(cands, ""), nil
+	}
+
+	versions, ok := s.versions[name]
+	if !ok {
+		var err error
+		versions, err = s.src.Versions(name)
+		if err != nil {
+			return nil, err
+		}
+		sort.Sort(sort.Reverse(semver.Collection(versions)))
+		s.versions[name] = versions
+	}
+
+	var cands []string
+	for _, v := range versions {
+		if check(v) {
+			cands = append(cands, v.Original())
+		}
+	}
+
+	return preferHints(cands, hints), nil
+}
+
+// preferHints moves the candidates matching hints to the front in the order
+// of the hints. Hints that are not candidates are ignored.
+func preferHints(cands []string, hints []Requirement) []string {
+	if len(hints) == 0 {
+		return cands
+	}
+
+	var front []string
+	for _, h := range hints {
+		hv, err := semver.NewVersion(h.Reference)
+		for _, c := range cands {
+			if c == h.Reference {
+				front = appendUnique(front, c)
+			} else if cv, cerr := semver.NewVersion(c); err == nil && cerr == nil && cv.Equal(hv) {
+				front = appendUnique(front, c)
+			}
+		}
+	}
+	for _, c := range cands {
+		front = appendUnique(front, c)
+	}
+
+	return front
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, l := range list {
+		if l == s {
+			return list
+		}
+	}
+
+	return append(list, s)
+}
+
+// exactReference returns the reference to use from requirements for a branch,
+// tag or commit id. A locked version is used when the semantic versions asked
+// for by dependencies are the same. Otherwise the glide.yaml file of the
+// project decides between different ones. When only dependencies ask for
+// references the first is used and the others are displayed as warnings.
+func exactReference(refs []Requirement) (string, bool) {
+	for _, l := range refs {
+		if !l.Locked {
+			continue
+		}
+		for _, r := range refs {
+			if r.From == "" || r.Reference == l.Reference {
+				continue
+			}
+			if _, err := semver.NewVersion(r.Reference); err == nil {
+				return "", false
+			}
+		}
+		return l.Reference, true
+	}
+
+	for _, r := range refs {
+		if r.From == "" {
+			return r.Reference, true
+		}
+	}
+
+	return refs[0].Reference, true
+}
+
+// warnUnchecked displays the requirements on a package that the chosen
+// reference could not be compared with.
+func (s *solver) warnUnchecked(sol map[string]string) {
+	reqs, err := s.requirements()
+	if err != nil {
+		return
+	}
+
+	for _, name := range s.order {
+		ver := sol[name]
+		if ver == "" {
+			continue
+		}
+		_, verr := semver.NewVersion(ver)
+		on := requirementsOn(name, reqs)
+		locked := false
+		for _, r := range on {
+			locked = locked || r.Locked
+		}
+		for _, r := range on {
+			// The glide.yaml file was already used to choose a locked version.
+			// Hints do not need to be met.
+			if r.Hint || r.Reference == ver || (locked && r.From == "") {
+				continue
+			}
+			isRange, err := s.src.IsRange(name, r.Reference)
+			if err != nil || (isRange && verr == nil) {
+				continue
+			}
+			msg.Warn("Using %s %s, which could not be checked against %s", name, ver, r)
+		}
+	}
+}
+
+func requirementsOn(name string, reqs []Requirement) []Requirement {
+	var on []Requirement
+	for _, r := range reqs {
+		if r.Name == name {
+			on = append(on, r)
+		}
+	}
+
+	return on
+}
+
+// vcsSource is a VersionSource reading from the repositories in the cache.
+//
+// The requirements of a version are read from its glide.yaml file in Git
+// repositories. Otherwise, or when there is no glide.yaml file for the
+// version, the version is checked out to import its configuration. Versions
+// of snapshots from a proxy other than the cached one are downloaded to read
+// them. The requirements imported from the checkout while resolving the
+// dependencies are used for the default branch. When nestedLocks is true the
+// glide.lock file of the version adds hints to its requirements.
+type vcsSource struct {
+	conf        *cfg.Config
+	observed    map[string][]Requirement
+	ranges      map[string]bool
+	nestedLocks bool
+}
+
+func newVcsSource(conf *cfg.Config, observed map[string][]Requirement, nestedLocks bool) *vcsSource {
+	return &vcsSource{
+		conf:        conf,
+		observed:    observed,
+		ranges:      make(map[string]bool),
+		nestedLocks: nestedLocks,
+	}
+}
+
+func (s *vcsSource) dir(name string) (*cfg.Dependency, string, error) {
+	dep := s.conf.Imports.Get(name)
+	if dep == nil {
+		dep = s.conf.DevImports.Get(name)
+	}
+	if dep == nil {
+		dep = &cfg.Dependency{Name: name}
+	}
+
+	key, err := cache.Key(dep.Remote())
+	if err != nil {
+		return nil, "", err
+	}
+
+	return dep, filepath.Join(cache.Location(), "src", key), nil
+}
+
+func (s *vcsSource) repo(name string) (vcs.Repo, error) {
+	dep, dir, err := s.dir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return dep.GetRepo(dir)
+}
+
+// resolvedAt returns true when a version of a package is the revision its
+// imports were resolved at.
+func (s *vcsSource) resolvedAt(name, version, rev string) bool {
+	dep, dir, err := s.dir(name)
+	if err != nil {
+		return false
+	}
+	if _, srev, err := readSnapshotMarker(dir); err == nil {
+		return srev == rev && snapshotMatches(version, srev, dir)
+	}
+
+	repo, err := dep.GetRepo(dir)
+	if err != nil {
+		return false
+	}
+	key := filepath.Base(dir)
+	if err := cache.Lock(key); err != nil {
+		return false
+	}
+	defer cache.Unlock(key)
+	ci, err := repo.CommitInfo(version)
+
+	return err == nil && ci.Commit == rev
+}
+
+func (s *vcsSource) Versions(name string) ([]*semver.Version, error) {
+	if _, dir, err := s.dir(name); err == nil {
+		if refs, ok, err := snapshotRefs(name, dir); ok {
+			if err != nil {
+				return nil, fmt.Errorf("Unable to read the versions of %s from the proxy: %s", name, err)
+			}
+			return getSemVers(refs), nil
+		}
+	}
+
+	dep, dir, err := s.dir(name)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read the versions of %s: %s", name, err)
+	}
+	repo, err := dep.GetRepo(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read the versions of %s: %s", name, err)
+	}
+	key := filepath.Base(dir)
+	if err := cache.Lock(key); err != nil {
+		return nil, err
+	}
+	refs, err := getAllVcsRefs(repo)
+	cache.Unlock(key)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read the versions of %s: %s", name, err)
+	}
+
+	return getSemVers(refs), nil
+}
+
+func (s *vcsSource) IsRange(name, ref string) (bool, error) {
+	k := name + "@" + ref
+	if r, ok := s.ranges[k]; ok {
+		return r, nil
+	}
+
+	// References in Git can begin with a ^ which is similar to semver.
+	// If there is a ^ prefix we assume it's a semver constraint rather than
+	// part of the git/VCS commit id.
+	var r bool
+	if _, dir, err := s.dir(name); err == nil && isSnapshot(dir) {
+		r = snapshotIsRange(name, dir, ref)
+	} else if repo, err := s.repo(name); err == nil {
+		r = !repo.IsReference(ref) || strings.HasPrefix(ref, "^")
+	} else {
+		_, err := semver.NewConstraint(ref)
+		r = err == nil
+	}
+	s.ranges[k] = r
+
+	return r, nil
+}
+
+func (s *vcsSource) Requirements(name, version string) ([]Requirement, error) {
+	if version == "" {
+		return append([]Requirement{}, s.observed[name]...), nil
+	}
+
+	dep, dir, err := s.dir(name)
+	if err != nil {
+		return nil, err
+	}
+	if base, rev, err := readSnapshotMarker(dir); err == nil {
+		if snapshotMatches(version, rev, dir) {
+			return s.importRequirements(name, dir)
+		}
+		return s.snapshotRequirements(base, name, version)
+	}
+
+	repo, err := dep.GetRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+	key := filepath.Base(dir)
+	if err := cache.Lock(key); err != nil {
+		return nil, err
+	}
+	defer cache.Unlock(key)
+
+	if repo.Vcs() == vcs.Git {
+		if out, err := repo.RunFromDir("git", "show", version+":glide.yaml"); err == nil {
+			conf, err := cfg.ConfigFromYaml(out)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to read the glide.yaml file of %s %s: %s", name, version, err)
+			}
+			var reqs []Requirement
+			for _, d := range conf.Imports {
+				reqs = append(reqs, Requirement{Name: d.Name, Reference: d.Reference})
+			}
+			if s.nestedLocks {
+				if out, err := repo.RunFromDir("git", "show", version+":glide.lock"); err == nil {
+					reqs = append(reqs, nestedLockHints(name, out)...)
+				}
+			}
+			return reqs, nil
+		}
+	}
+
+	// Other configuration files and version control systems are read from a
+	// checkout of the version.
+	prev, err := repo.Version()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateVersion(version); err != nil {
+		return nil, fmt.Errorf("Unable to check out %s %s: %s", name, version, err)
+	}
+	defer func() {
+		if err := repo.UpdateVersion(prev); err != nil {
+			msg.Warn("Unable to set %s back to %s: %s", name, prev, err)
+		}
+	}()
+
+	return s.importRequirements(name, dir)
+}
+
+// snapshotRequirements downloads a snapshot of a version from the proxy to
+// read its requirements. The cached snapshot is left as it is.
+func (s *vcsSource) snapshotRequirements(base, name, version string) ([]Requirement, error) {
+	if cache.Offline {
+		return nil, fmt.Errorf("Offline mode. Only the cached snapshot of %s can be read", name)
+	}
+
+	tmp, err := ioutil.TempDir("", "glide-snapshot")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	dir := filepath.Join(tmp, "src")
+	if _, err := syncSnapshot(base, &cfg.Dependency{Name: name, Reference: version}, dir); err != nil {
+		return nil, err
+	}
+
+	return s.importRequirements(name, dir)
+}
+
+// importRequirements reads the requirements in the configuration files of
+// the copy of a package in dir.
+func (s *vcsSource) importRequirements(name, dir string) ([]Requirement, error) {
+	_, deps, err := importer.Import(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var reqs []Requirement
+	for _, d := range deps {
+		reqs = append(reqs, Requirement{Name: d.Name, Reference: d.Reference})
+	}
+	if s.nestedLocks {
+		if yml, err := ioutil.ReadFile(filepath.Join(dir, gpath.LockFile)); err == nil {
+			reqs = append(reqs, nestedLockHints(name, yml)...)
+		}
+	}
+
+	return reqs, nil
+}
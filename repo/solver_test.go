@@ -0,0 +1,340 @@
+package repo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/semver"
+)
+
+type testSource struct {
+	versions map[string][]string
+	reqs     map[string][]Requirement
+	errs     map[string]error
+}
+
+func (s *testSource) Versions(name string) ([]*semver.Version, error) {
+	return getSemVers(s.versions[name]), nil
+}
+
+func (s *testSource) IsRange(name, ref string) (bool, error) {
+	_, err := semver.NewConstraint(ref)
+	return err == nil, nil
+}
+
+func (s *testSource) Requirements(name, version string) ([]Requirement, error) {
+	if err := s.errs[name+"@"+version]; err != nil {
+		return nil, err
+	}
+	return append([]Requirement{}, s.reqs[name+"@"+version]...), nil
+}
+
+func TestSolveBacktracks(t *testing.T) {
+	src := &testSource{
+		versions: map[string][]string{
+			"a": {"v1.0.0", "v1.1.0", "v1.2.0"},
+			"c": {"v1.0.0", "v1.5.0", "v2.0.0"},
+		},
+		reqs: map[string][]Requirement{
+			"a@v1.2.0": {{Name: "c", Reference: "^2.0.0"}},
+			"a@v1.1.0": {{Name: "c", Reference: "^1.0.0"}},
+			"a@v1.0.0": {{Name: "c", Reference: "^1.0.0"}},
+		},
+	}
+	root := []Requirement{
+		{Name: "a", Reference: "^1.0.0"},
+		{Name: "c", Reference: "~1.5.0"},
+	}
+
+	sol, err := Solve(src, root, []string{"a", "c"})
+	if err != nil {
+		t.Fatalf("Unexpected error solving: %s", err)
+	}
+	if sol["a"] != "v1.1.0" {
+		t.Errorf("Expected a v1.1.0 but got %s", sol["a"])
+	}
+	if sol["c"] != "v1.5.0" {
+		t.Errorf("Expected c v1.5.0 but got %s", sol["c"])
+	}
+}
+
+func TestSolveUnreadable(t *testing.T) {
+	src := &testSource{
+		versions: map[string][]string{
+			"a": {"v1.0.0", "v1.1.0"},
+			"c": {"v1.0.0", "v2.0.0"},
+		},
+		reqs: map[string][]Requirement{
+			"a@v1.0.0": {{Name: "c", Reference: "^1.0.0"}},
+		},
+		errs: map[string]error{
+			"a@v1.1.0": errors.New("invalid glide.yaml"),
+		},
+	}
+	root := []Requirement{{Name: "a", Reference: "^1.0.0"}}
+
+	sol, err := Solve(src, root, []string{"a", "c"})
+	if err != nil {
+		t.Fatalf("Unexpected error solving: %s", err)
+	}
+	if sol["a"] != "v1.0.0" {
+		t.Errorf("Expected a v1.0.0 since the requirements of v1.1.0 can not be read but got %s", sol["a"])
+	}
+	if sol["c"] != "v1.0.0" {
+		t.Errorf("Expected c v1.0.0 but got %s", sol["c"])
+	}
+}
+
+func TestSolveUnconstrained(t *testing.T) {
+	src := &testSource{
+		versions: map[string][]string{
+			"b": {"v1.0.0", "v2.0.0"},
+		},
+		reqs: map[string][]Requirement{
+			"a@": {{Name: "b", Reference: "^1.0.0"}, {Name: "d", Reference: "^1.0.0"}},
+		},
+	}
+
+	sol, err := Solve(src, nil, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Unexpected error solving: %s", err)
+	}
+	if sol["a"] != "" {
+		t.Errorf("Expected the default branch for a but got %s", sol["a"])
+	}
+	if sol["b"] != "v1.0.0" {
+		t.Errorf("Expected b v1.0.0 but got %s", sol["b"])
+	}
+	if _, ok := sol["d"]; ok {
+		t.Error("Expected d to be skipped since it is not a package being solved")
+	}
+}
+
+func TestSolveReferences(t *testing.T) {
+	src := &testSource{
+		reqs: map[string][]Requirement{
+			"a@": {{Name: "b", Reference: "develop"}},
+			"c@": {{Name: "d", Reference: "feature"}},
+			"e@": {{Name: "d", Reference: "master"}},
+		},
+	}
+
+	root := []Requirement{{Name: "b", Reference: "master"}}
+	sol, err := Solve(src, root, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Unexpected error solving: %s", err)
+	}
+	if sol["b"] != "master" {
+		t.Errorf("Expected the glide.yaml reference master for b but got %s", sol["b"])
+	}
+
+	// Different references from dependencies keep the first one.
+	sol, err = Solve(src, nil, []string{"c", "d", "e"})
+	if err != nil {
+		t.Fatalf("Unexpected error solving: %s", err)
+	}
+	if sol["d"] != "feature" {
+		t.Errorf("Expected the first reference feature for d but got %s", sol["d"])
+	}
+
+	// A range in glide.yaml is kept over a branch asked for by a dependency.
+	src = &testSource{
+		versions: map[string][]string{
+			"foo": {"v1.1.0", "v1.2.0", "v1.3.0"},
+		},
+		reqs: map[string][]Requirement{
+			"a@": {{Name: "foo", Reference: "master"}},
+		},
+	}
+	root = []Requirement{{Name: "foo", Reference: "^1.2.0"}}
+	sol, err = Solve(src, root, []string{"a", "foo"})
+	if err != nil {
+		t.Fatalf("Unexpected error solving: %s", err)
+	}
+	if sol["foo"] != "v1.3.0" {
+		t.Errorf("Expected the glide.yaml range to choose foo v1.3.0 but got %s", sol["foo"])
+	}
+}
+
+func TestSolveConflict(t *testing.T) {
+	src := &testSource{
+		versions: map[string][]string{
+			"a": {"v1.0.0", "v1.1.0"},
+			"b": {"v1.0.0"},
+			"c": {"v1.0.0", "v1.5.0", "v2.0.0"},
+		},
+		reqs: map[string][]Requirement{
+			"a@v1.1.0": {{Name: "c", Reference: "^2.0.0"}},
+			"a@v1.0.0": {{Name: "c", Reference: "^2.0.0"}},
+			"b@v1.0.0": {{Name: "c", Reference: ">=1.0.0"}},
+		},
+	}
+	root := []Requirement{
+		{Name: "a", Reference: "^1.0.0"},
+		{Name: "b", Reference: "^1.0.0"},
+		{Name: "c", Reference: "^1.0.0"},
+	}
+
+	_, err := Solve(src, root, []string{"a", "b", "c"})
+	ce, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("Expected a conflict but got %v", err)
+	}
+	if ce.Name != "c" {
+		t.Errorf("Expected a conflict on c but got %s", ce.Name)
+	}
+
+	// The requirement from b does not take part in the conflict.
+	if len(ce.Requirements) != 2 {
+		t.Fatalf("Expected 2 conflicting requirements but got %d: %s", len(ce.Requirements), ce)
+	}
+	if !strings.Contains(ce.Error(), "^1.0.0 (required by glide.yaml)") || !strings.Contains(ce.Error(), "^2.0.0 (required by a v1.1.0)") {
+		t.Errorf("Unexpected conflict explanation: %s", ce)
+	}
+}
+
+func TestSolveOverride(t *testing.T) {
+	src := &testSource{
+		versions: map[string][]string{
+			"a": {"v1.0.0"},
+			"c": {"v1.0.0", "v1.0.1", "v2.0.0"},
+		},
+		reqs: map[string][]Requirement{
+			"a@v1.0.0": {{Name: "c", Reference: "~1.0.0"}},
+		},
+	}
+	root := []Requirement{
+		{Name: "a", Reference: "^1.0.0"},
+		{Name: "c", Reference: "^2.0.0", Override: true},
+	}
+
+	sol, err := Solve(src, root, []string{"a", "c"})
+	if err != nil {
+		t.Fatalf("Unexpected error solving: %s", err)
+	}
+	if sol["c"] != "v2.0.0" {
+		t.Errorf("Expected the override c v2.0.0 but got %s", sol["c"])
+	}
+}
+
+func TestSolveLocked(t *testing.T) {
+	src := &testSource{
+		versions: map[string][]string{
+			"a": {"v1.0.0", "v1.1.0", "v1.2.0"},
+			"c": {"v1.0.0", "v1.5.0", "v2.0.0"},
+		},
+		reqs: map[string][]Requirement{
+			"a@v1.2.0": {{Name: "c", Reference: "v2.0.0"}},
+			"a@v1.1.0": {{Name: "c", Reference: "^1.0.0"}},
+			"a@v1.0.0": {{Name: "c", Reference: "^1.0.0"}},
+		},
+	}
+	locked := Requirement{Name: "c", Reference: "v1.5.0", Locked: true}
+
+	// The locked version is kept over newer ones and branches in glide.yaml.
+	root := []Requirement{
+		{Name: "a", Reference: "^1.0.0"},
+		{Name: "c", Reference: "master"},
+		locked,
+	}
+	sol, err := Solve(src, root, []string{"a", "c"})
+	if err != nil {
+		t.Fatalf("Unexpected error solving: %s", err)
+	}
+	if sol["a"] != "v1.1.0" || sol["c"] != "v1.5.0" {
+		t.Errorf("Expected a v1.1.0 and c v1.5.0 but got %v", sol)
+	}
+
+	// A version of a that needs c to change fails.
+	root = []Requirement{{Name: "a", Reference: "v1.2.0"}, locked}
+	_, err = Solve(src, root, []string{"a", "c"})
+	cerr, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("Expected a conflict but got %v", err)
+	}
+	if cerr.Name != "c" || !strings.Contains(cerr.Error(), "v1.5.0 (required by glide.lock)") {
+		t.Errorf("Unexpected conflict %s", cerr)
+	}
+
+	i := &Installer{Locked: map[string]*cfg.Lock{"c": {Name: "c", Version: "v1.5.0"}}}
+	if err := i.lockedConflict(cerr); !strings.Contains(err.Error(), "requires changing c") {
+		t.Errorf("Expected the conflict to explain c needs to change but got %s", err)
+	}
+}
+
+func TestSolveNestedLocks(t *testing.T) {
+	src := &testSource{
+		versions: map[string][]string{
+			"a": {"v1.0.0", "v1.1.0"},
+			"c": {"v1.0.0", "v1.5.0", "v2.0.0"},
+		},
+		reqs: map[string][]Requirement{
+			"a@v1.1.0": {
+				{Name: "c", Reference: "^1.0.0"},
+				{Name: "c", Reference: "v1.0.0", Hint: true},
+				{Name: "d", Reference: "abc123", Hint: true},
+			},
+			"a@v1.0.0": {
+				{Name: "c", Reference: "^1.0.0"},
+				{Name: "c", Reference: "v2.0.0", Hint: true},
+			},
+		},
+	}
+
+	// The version locked by a is used over newer ones.
+	root := []Requirement{{Name: "a", Reference: "^1.0.0"}}
+	sol, err := Solve(src, root, []string{"a", "c", "d"})
+	if err != nil {
+		t.Fatalf("Unexpected error solving: %s", err)
+	}
+	if sol["a"] != "v1.1.0" || sol["c"] != "v1.0.0" {
+		t.Errorf("Expected a v1.1.0 and c v1.0.0 but got %v", sol)
+	}
+	if sol["d"] != "abc123" {
+		t.Errorf("Expected the locked commit for d but got %s", sol["d"])
+	}
+
+	// A hint outside of the ranges asked for is ignored.
+	root = []Requirement{{Name: "a", Reference: "v1.0.0"}}
+	sol, err = Solve(src, root, []string{"a", "c"})
+	if err != nil {
+		t.Fatalf("Unexpected error solving: %s", err)
+	}
+	if sol["c"] != "v1.5.0" {
+		t.Errorf("Expected c v1.5.0 but got %s", sol["c"])
+	}
+
+	// The requirements of the project still win.
+	root = []Requirement{{Name: "a", Reference: "v1.1.0"}, {Name: "c", Reference: "~1.5.0"}}
+	sol, err = Solve(src, root, []string{"a", "c"})
+	if err != nil {
+		t.Fatalf("Unexpected error solving: %s", err)
+	}
+	if sol["c"] != "v1.5.0" {
+		t.Errorf("Expected c v1.5.0 but got %s", sol["c"])
+	}
+}
+
+func TestNestedLockHints(t *testing.T) {
+	yml := []byte(`hash: abc
+updated: 2016-01-01T00:00:00Z
+imports:
+- name: github.com/example/b
+  version: 1234567
+- name: github.com/example/c
+  path: ../c
+testImports:
+- name: github.com/example/d
+  version: 89abcde
+`)
+	hints := nestedLockHints("github.com/example/a", yml)
+	if len(hints) != 1 {
+		t.Fatalf("Expected 1 hint but got %v", hints)
+	}
+	h := hints[0]
+	if h.Name != "github.com/example/b" || h.Reference != "1234567" || !h.Hint {
+		t.Errorf("Unexpected hint %v", h)
+	}
+}
@@ -41,105 +41,36 @@ func VcsUpdate(dep *cfg.Dependency, force bool, updated *UpdateTracker) error {
 		return nil
 	}
 
+	// Local dependencies are used as they are.
+	if dep.Path != "" {
+		msg.With(msg.Fields{"package": dep.Name, "action": "fetching"}).Info("--> Using %s from the local path %s", dep.Name, dep.Path)
+		return checkLocal(dep)
+	}
+
 	key, err := cp.Key(dep.Remote())
 	if err != nil {
-		msg.Die("Cache key generation error: %s", err)
+		return fmt.Errorf("Cache key generation error: %s", err)
 	}
 	location := cp.Location()
 	dest := filepath.Join(location, "src", key)
 
 	// If destination doesn't exist we need to perform an initial checkout.
 	if _, err := os.Stat(dest); os.IsNotExist(err) {
-		msg.Info("--> Fetching %s", dep.Name)
+		msg.With(msg.Fields{"package": dep.Name, "action": "fetching"}).Info("--> Fetching %s", dep.Name)
 		if err = VcsGet(dep); err != nil {
 			msg.Warn("Unable to checkout %s\n", dep.Name)
 			return err
 		}
 	} else {
 		// At this point we have a directory for the package.
-		msg.Info("--> Fetching updates for %s", dep.Name)
-
-		// When the directory is not empty and has no VCS directory it's
-		// a vendored files situation.
-		empty, err := gpath.IsDirectoryEmpty(dest)
-		if err != nil {
-			return err
-		}
-		_, err = v.DetectVcsFromFS(dest)
-		if empty == true && err == v.ErrCannotDetectVCS {
-			msg.Warn("Cached version of %s is an empty directory. Fetching a new copy of the dependency", dep.Name)
-			msg.Debug("Removing empty directory %s", dest)
-			err := os.RemoveAll(dest)
-			if err != nil {
-				return err
-			}
-			if err = VcsGet(dep); err != nil {
-				msg.Warn("Unable to checkout %s\n", dep.Name)
-				return err
-			}
-		} else {
-			repo, err := dep.GetRepo(dest)
-
-			// Tried to checkout a repo to a path that does not work. Either the
-			// type or endpoint has changed. Force is being passed in so the old
-			// location can be removed and replaced with the new one.
-			// Warning, any changes in the old location will be deleted.
-			// TODO: Put dirty checking in on the existing local checkout.
-			if (err == v.ErrWrongVCS || err == v.ErrWrongRemote) && force == true {
-				newRemote := dep.Remote()
-
-				msg.Warn("Replacing %s with contents from %s\n", dep.Name, newRemote)
-				rerr := os.RemoveAll(dest)
-				if rerr != nil {
-					return rerr
-				}
-				if err = VcsGet(dep); err != nil {
-					msg.Warn("Unable to checkout %s\n", dep.Name)
-					return err
-				}
-
-				repo, err = dep.GetRepo(dest)
-				if err != nil {
-					return err
-				}
-			} else if err != nil {
-				return err
-			} else if repo.IsDirty() {
-				return fmt.Errorf("%s contains uncommitted changes. Skipping update", dep.Name)
-			}
-
-			ver := dep.Reference
-			if ver == "" {
-				ver = defaultBranch(repo)
-			}
-			// Check if the current version is a tag or commit id. If it is
-			// and that version is already checked out we can skip updating
-			// which is faster than going out to the Internet to perform
-			// an update.
-			if ver != "" {
-				version, err := repo.Version()
-				if err != nil {
-					return err
-				}
-				ib, err := isBranch(ver, repo)
-				if err != nil {
-					return err
-				}
-
-				// If the current version equals the ref and it's not a
-				// branch it's a tag or commit id so we can skip
-				// performing an update.
-				if version == ver && !ib {
-					msg.Debug("%s is already set to version %s. Skipping update", dep.Name, dep.Reference)
-					return nil
-				}
-			}
-
-			if err := repo.Update(); err != nil {
-				msg.Warn("Download failed.\n")
-				return err
-			}
-		}
+		msg.With(msg.Fields{"package": dep.Name, "action": "fetching"}).Info("--> Fetching updates for %s", dep.Name)
+		// The fetch limits are held for each attempt so other fetches can
+		// run while waiting to retry.
+		return withRetry(dep.Name, "Updating", nil, func() error {
+			release := acquireFetch(dep)
+			defer release()
+			return fetcherFor(dep).Update(dep, dest, force)
+		})
 	}
 
 	return nil
@@ -155,13 +86,32 @@ func VcsVersion(dep *cfg.Dependency) error {
 		return nil
 	}
 
+	// A local dependency is used at whatever version it is checked out to.
+	if dep.Path != "" {
+		if err := checkLocal(dep); err != nil {
+			return err
+		}
+		dep.Pin = localVersion(dep)
+		return nil
+	}
+
 	key, err := cp.Key(dep.Remote())
 	if err != nil {
-		msg.Die("Cache key generation error: %s", err)
+		return fmt.Errorf("Cache key generation error: %s", err)
 	}
 	location := cp.Location()
 	cwd := filepath.Join(location, "src", key)
 
+	// The version of an archive is its checksum.
+	if dep.Archive != "" {
+		dep.Pin, err = archiveChecksum(cwd)
+		return err
+	}
+
+	if isSnapshot(cwd) {
+		return snapshotVersion(dep, cwd)
+	}
+
 	// If there is no reference configured there is nothing to set.
 	if dep.Reference == "" {
 		// Before exiting update the pinned version
@@ -193,11 +143,25 @@ func VcsVersion(dep *cfg.Dependency) error {
 	}
 
 	ver := dep.Reference
+
+	// Shallow clones may be missing the tag or commit asked for. Semantic
+	// version constraints only need the tags.
+	if isShallow(repo) && !repo.IsReference(ver) {
+		if _, cerr := semver.NewConstraint(ver); cerr == nil {
+			err = fetchShallowTags(repo)
+		} else {
+			err = deepen(repo, ver)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
 	// References in Git can begin with a ^ which is similar to semver.
 	// If there is a ^ prefix we assume it's a semver constraint rather than
 	// part of the git/VCS commit id.
 	if repo.IsReference(ver) && !strings.HasPrefix(ver, "^") {
-		msg.Info("--> Setting version for %s to %s.\n", dep.Name, ver)
+		msg.With(msg.Fields{"package": dep.Name, "version": ver, "action": "setting version"}).Info("--> Setting version for %s to %s.\n", dep.Name, ver)
 	} else {
 
 		// Create the constraint first to make sure it's valid before
@@ -232,9 +196,9 @@ func VcsVersion(dep *cfg.Dependency) error {
 			}
 		}
 		if found {
-			msg.Info("--> Detected semantic version. Setting version for %s to %s", dep.Name, ver)
+			msg.With(msg.Fields{"package": dep.Name, "version": ver, "action": "setting version"}).Info("--> Detected semantic version. Setting version for %s to %s", dep.Name, ver)
 		} else {
-			msg.Warn("--> Unable to find semantic version for constraint %s %s", dep.Name, ver)
+			msg.With(msg.Fields{"package": dep.Name, "version": ver, "action": "setting version"}).Warn("--> Unable to find semantic version for constraint %s %s", dep.Name, ver)
 		}
 	}
 	if err := repo.UpdateVersion(ver); err != nil {
@@ -252,45 +216,43 @@ func VcsVersion(dep *cfg.Dependency) error {
 //
 // VcsGet installs into the cache.
 func VcsGet(dep *cfg.Dependency) error {
+	if dep.Path != "" {
+		return checkLocal(dep)
+	}
 
 	key, err := cp.Key(dep.Remote())
 	if err != nil {
-		msg.Die("Cache key generation error: %s", err)
+		return fmt.Errorf("Cache key generation error: %s", err)
 	}
 	location := cp.Location()
 	d := filepath.Join(location, "src", key)
 
-	repo, err := dep.GetRepo(d)
-	if err != nil {
-		return err
-	}
-	// If the directory does not exist this is a first cache.
-	if _, err = os.Stat(d); os.IsNotExist(err) {
-		msg.Debug("Adding %s to the cache for the first time", dep.Name)
-		err = repo.Get()
-		if err != nil {
-			return err
-		}
-		branch := findCurrentBranch(repo)
-		if branch != "" {
-			msg.Debug("Saving default branch for %s", repo.Remote())
-			c := cp.RepoInfo{DefaultBranch: branch}
-			err = cp.SaveRepoData(key, c)
-			if err == cp.ErrCacheDisabled {
-				msg.Debug("Unable to cache default branch because caching is disabled")
-			} else if err != nil {
-				msg.Debug("Error saving %s to cache. Error: %s", repo.Remote(), err)
-			}
+	if cp.Offline {
+		if _, err = os.Stat(d); os.IsNotExist(err) {
+			return errOffline(dep)
 		}
-	} else {
-		msg.Debug("Updating %s in the cache", dep.Name)
-		err = repo.Update()
-		if err != nil {
-			return err
+		msg.Debug("Offline mode. Using the cached copy of %s without fetching updates", dep.Name)
+		return nil
+	}
+
+	// A partial copy from a failed first fetch is removed before retrying.
+	_, serr := os.Stat(d)
+	cleanup := func() {
+		if serr != nil {
+			os.RemoveAll(d)
 		}
 	}
+	return withRetry(dep.Name, "Fetching", cleanup, func() error {
+		release := acquireFetch(dep)
+		defer release()
+		return fetcherFor(dep).Get(dep, d)
+	})
+}
 
-	return nil
+// errOffline is returned when a dependency is needed that is not in the cache
+// while in offline mode.
+func errOffline(dep *cfg.Dependency) error {
+	return fmt.Errorf("%s (%s) is not in the cache at %s and can not be fetched in offline mode", dep.Name, dep.Remote(), cp.Location())
 }
 
 // filterArchOs indicates a dependency should be filtered out because it is
@@ -356,15 +318,19 @@ func defaultBranch(repo v.Repo) string {
 	key, kerr := cp.Key(repo.Remote())
 	var d cp.RepoInfo
 	if kerr == nil {
-		d, err := cp.RepoData(key)
+		rd, err := cp.RepoData(key)
 		if err == nil {
-			if d.DefaultBranch != "" {
-				return d.DefaultBranch
+			if rd.DefaultBranch != "" {
+				return rd.DefaultBranch
 			}
+			d = *rd
 		}
 	}
 
 	// If we don't have it in the store try some APIs
+	if cp.Offline {
+		return ""
+	}
 	r := repo.Remote()
 	u, err := url.Parse(r)
 	if err != nil {
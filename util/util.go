@@ -87,6 +87,14 @@ func getRootFromGoGet(pkg string) string {
 		return p
 	}
 
+	if CachedRoot != nil {
+		if root, ok := CachedRoot(pkg); ok {
+			addToRemotePackageCache(root, root)
+			return root
+		}
+		return pkg
+	}
+
 	vcsURL := "https://" + pkg
 	u, err := url.Parse(vcsURL)
 	if err != nil {
@@ -118,6 +126,11 @@ func getRootFromGoGet(pkg string) string {
 	return nu
 }
 
+// CachedRoot, when set, finds the root of a package instead of the go get
+// lookup, returning false when it is not known. It is set in offline mode,
+// where the network is unavailable, to look for the package in the cache.
+var CachedRoot func(pkg string) (string, bool)
+
 // The caching is not concurrency safe but should be made to be that way.
 // This implementation is far too much of a hack... rewrite needed.
 var remotePackageCache = make(map[string]string)